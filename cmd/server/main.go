@@ -12,7 +12,9 @@ import (
 	"store/internal/api"
 	"store/internal/repository"
 	"store/internal/service"
+	"store/pkg/auth"
 	"store/pkg/consul"
+	"store/pkg/ratelimit"
 	"store/pkg/zap"
 	"syscall"
 	"time"
@@ -20,6 +22,7 @@ import (
 	"github.com/gin-gonic/gin"
 	consulapi "github.com/hashicorp/consul/api"
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -64,15 +67,76 @@ func main() {
 	// Initialize repositories and service
 	cartCollection := mongoClient.Database(cfg.MongoDB).Collection("carts")
 	cartHistoryCollection := mongoClient.Database(cfg.MongoDB).Collection("cart_history")
+	wishlistCollection := mongoClient.Database(cfg.MongoDB).Collection("wishlists")
+	cartMemberCollection := mongoClient.Database(cfg.MongoDB).Collection("cart_members")
+	shareLinkCollection := mongoClient.Database(cfg.MongoDB).Collection("share_links")
+	budgetCollection := mongoClient.Database(cfg.MongoDB).Collection("budgets")
 	historyRepo := repository.NewCartHistoryRepository(cartHistoryCollection, cartCollection)
 	cartRepo := repository.NewCartRepository(cartCollection, cartHistoryCollection)
-	cartService := service.NewCartService(cartRepo, *historyRepo, consulClient)
+	wishlistRepo := repository.NewWishlistRepository(wishlistCollection)
+	cartMemberRepo := repository.NewCartMemberRepository(cartMemberCollection)
+	shareLinkRepo := repository.NewShareLinkRepository(shareLinkCollection)
+	budgetRepo := repository.NewBudgetRepository(budgetCollection)
+	// Service tokens are optional; without a signing key the caller's token is forwarded instead
+	var signer *auth.ServiceTokenSigner
+	if cfg.ServiceAuth.SigningKey != "" {
+		signer, err = auth.NewServiceTokenSigner(cfg.ServiceAuth)
+		if err != nil {
+			logger.Fatalf("Failed to initialize service token signer: %v", err)
+		}
+	} else {
+		logger.Warn("SERVICE_TOKEN_KEY is not set, forwarding user tokens to other services")
+	}
+
+	studentDirectory, err := service.NewStudentDirectory(cfg.Students, consulClient, signer)
+	if err != nil {
+		logger.Fatalf("Failed to initialize student directory: %v", err)
+	}
+	cartService := service.NewCartService(cartRepo, *historyRepo, cartMemberRepo, budgetRepo, consulClient, studentDirectory, signer, cfg.Cart)
+	wishlistService := service.NewWishlistService(wishlistRepo, cartService, consulClient, studentDirectory, signer)
+	budgetService := service.NewBudgetService(budgetRepo)
+
+	// Share links are optional; without a signing key they answer 503
+	var shareSigner *auth.ShareTokenSigner
+	if cfg.ShareLinks.SigningKey != "" {
+		shareSigner, err = auth.NewShareTokenSigner(cfg.ShareLinks)
+		if err != nil {
+			logger.Fatalf("Failed to initialize share link signer: %v", err)
+		}
+	} else {
+		logger.Warn("SHARE_LINK_KEY is not set, cart share links are disabled")
+	}
+	shareLinkService := service.NewShareLinkService(shareLinkRepo, cartRepo, cartMemberRepo, studentDirectory, shareSigner, cfg.ShareLinks)
+
+	// Initialize token verification
+	verifier, err := auth.NewVerifier(cfg.Auth)
+	if err != nil {
+		logger.Fatalf("Failed to initialize token verifier: %v", err)
+	}
+	defer verifier.Close()
+
+	// Initialize rate limiting, shared through Redis when configured
+	var rateLimitStore ratelimit.Store
+	if cfg.RateLimit.RedisAddr != "" {
+		redisClient := redis.NewClient(&redis.Options{Addr: cfg.RateLimit.RedisAddr})
+		defer redisClient.Close()
+		rateLimitStore = ratelimit.NewRedisStore(redisClient)
+	} else {
+		rateLimitStore = ratelimit.NewMemoryStore()
+	}
+	limiter := api.NewRateLimiter(rateLimitStore, cfg.RateLimit)
+
+	cors, err := api.CORS(cfg.CORS)
+	if err != nil {
+		logger.Fatalf("Invalid CORS configuration: %v", err)
+	}
 
 	// Set up router with Gin
 	router := gin.Default()
+	router.Use(api.SecurityHeaders(cfg.Security), cors)
 
 	// Register handlers
-	api.RegisterHandlers(router, cartService)
+	api.RegisterHandlers(router, cartService, wishlistService, shareLinkService, budgetService, verifier, limiter)
 
 	// Initialize HTTP server
 	server := &http.Server{
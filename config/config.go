@@ -1,6 +1,12 @@
 package config
 
-import "os"
+import (
+	"os"
+	"store/pkg/constants"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Consul struct {
 	Host string `mapstructure:"host" validate:"required"`
@@ -34,6 +40,13 @@ type SettingConfig struct {
 	IgnoreLogUrls       []string `mapstructure:"ignoreLogUrls"`
 }
 
+type RedactionConfig struct {
+	// Fields are structured field names whose values are always masked.
+	Fields []string `mapstructure:"fields"`
+	// Patterns are regular expressions masked inside any logged string.
+	Patterns []string `mapstructure:"patterns"`
+}
+
 type ZapConfig struct {
 	Development bool   `mapstructure:"development"`
 	Caller      bool   `mapstructure:"caller"`
@@ -45,19 +58,132 @@ type ZapConfig struct {
 			Encoding string `mapstructure:"encoding"`
 		} `mapstructure:"console"`
 	} `mapstructure:"cores"`
+	Redaction RedactionConfig `mapstructure:"redaction"`
+}
+
+type AuthConfig struct {
+	HMACSecret     string        `mapstructure:"hmacSecret"`
+	RSAPublicKey   string        `mapstructure:"rsaPublicKey"`
+	ECDSAPublicKey string        `mapstructure:"ecdsaPublicKey"`
+	Issuer         string        `mapstructure:"issuer"`
+	Audience       string        `mapstructure:"audience"`
+	RolesClaim     string        `mapstructure:"rolesClaim"`
+	Leeway         time.Duration `mapstructure:"leeway"`
+	// JWKS is a file path or URL of a JSON Web Key Set used for kid-based key rotation.
+	JWKS                string        `mapstructure:"jwks"`
+	JWKSRefreshInterval time.Duration `mapstructure:"jwksRefreshInterval"`
+}
+
+type ServiceAuthConfig struct {
+	// SigningKey is an HMAC secret for HS* algorithms, or a PEM private key (content or path) otherwise.
+	SigningKey string        `mapstructure:"signingKey"`
+	Algorithm  string        `mapstructure:"algorithm"`
+	KeyID      string        `mapstructure:"keyID"`
+	Issuer     string        `mapstructure:"issuer"`
+	TTL        time.Duration `mapstructure:"ttl"`
+}
+
+type ShareLinkConfig struct {
+	// SigningKey is the HMAC secret share tokens are signed with; share links are disabled without it.
+	SigningKey string        `mapstructure:"signingKey"`
+	Issuer     string        `mapstructure:"issuer"`
+	DefaultTTL time.Duration `mapstructure:"defaultTTL"`
+	MaxTTL     time.Duration `mapstructure:"maxTTL"`
+}
+
+type StudentDirectoryConfig struct {
+	// Source selects the directory backend: "user-service" or "file".
+	Source   string        `mapstructure:"source"`
+	FilePath string        `mapstructure:"filePath"`
+	CacheTTL time.Duration `mapstructure:"cacheTTL"`
+}
+
+type CartConfig struct {
+	// PurchaseLimitScope counts product purchase limits per "student" cart or across all of a "teacher"'s carts.
+	PurchaseLimitScope string `mapstructure:"purchaseLimitScope"`
+	// WeeklyUsageBudget is the planned minimum usage time, in minutes per week, a student's cart may hold;
+	// zero disables it. The cart's planned uses are spread evenly over UsagePlanningWeeks weeks.
+	WeeklyUsageBudget  int `mapstructure:"weeklyUsageBudget"`
+	UsagePlanningWeeks int `mapstructure:"usagePlanningWeeks"`
+	// UsageBudgetMode is "warn" to flag carts over the budget or "reject" to refuse changes that exceed it.
+	UsageBudgetMode string `mapstructure:"usageBudgetMode"`
+	// Approval holds the rules that decide which carts need a supervisor's approval before checkout.
+	Approval ApprovalConfig `mapstructure:"approval"`
+}
+
+type ApprovalConfig struct {
+	// Enabled turns the approval workflow on. With no rule set, every cart needs approval.
+	Enabled bool `mapstructure:"enabled"`
+	// StoreTotalThreshold and ServiceTotalThreshold require approval of carts whose total goes past them; zero disables them.
+	StoreTotalThreshold   float64 `mapstructure:"storeTotalThreshold"`
+	ServiceTotalThreshold float64 `mapstructure:"serviceTotalThreshold"`
+	// Categories require approval of carts holding products of any of these categories, ignoring case.
+	Categories []string `mapstructure:"categories"`
+}
+
+type RateLimit struct {
+	// Rate is the number of requests per second refilled into the bucket.
+	Rate  float64 `mapstructure:"rate"`
+	Burst int     `mapstructure:"burst"`
+}
+
+type RateLimitConfig struct {
+	Enabled   bool      `mapstructure:"enabled"`
+	RedisAddr string    `mapstructure:"redisAddr"`
+	Default   RateLimit `mapstructure:"default"`
+	// Routes overrides the default per route, keyed by "METHOD /path" as registered in gin.
+	Routes map[string]RateLimit `mapstructure:"routes"`
+}
+
+type CORSConfig struct {
+	// AllowedOrigins accepts exact origins, "*" for any origin, or "*.example.com" for subdomains.
+	// AllowCredentials is off by default and cannot be combined with "*".
+	AllowedOrigins   []string      `mapstructure:"allowedOrigins"`
+	AllowedMethods   []string      `mapstructure:"allowedMethods"`
+	AllowedHeaders   []string      `mapstructure:"allowedHeaders"`
+	ExposedHeaders   []string      `mapstructure:"exposedHeaders"`
+	AllowCredentials bool          `mapstructure:"allowCredentials"`
+	MaxAge           time.Duration `mapstructure:"maxAge"`
+}
+
+type SecurityHeadersConfig struct {
+	// HSTSMaxAge of zero disables Strict-Transport-Security.
+	HSTSMaxAge            time.Duration `mapstructure:"hstsMaxAge"`
+	HSTSIncludeSubdomains bool          `mapstructure:"hstsIncludeSubdomains"`
+	ContentTypeNosniff    bool          `mapstructure:"contentTypeNosniff"`
+	ReferrerPolicy        string        `mapstructure:"referrerPolicy"`
 }
 
 type Config struct {
-	Port     string
-	MongoURI string
-	MongoDB  string
-	Consul   Consul           `mapstructure:"consul" validate:"required"`
-	Registry Registry         `mapstructure:"registry" validate:"required"`
-	App      AppConfiguration `mapstructure:"app"`
-	Zap      ZapConfig        `mapstructure:"zap"`
+	Port        string
+	MongoURI    string
+	MongoDB     string
+	Consul      Consul                 `mapstructure:"consul" validate:"required"`
+	Registry    Registry               `mapstructure:"registry" validate:"required"`
+	App         AppConfiguration       `mapstructure:"app"`
+	Zap         ZapConfig              `mapstructure:"zap"`
+	Auth        AuthConfig             `mapstructure:"auth"`
+	Students    StudentDirectoryConfig `mapstructure:"students"`
+	RateLimit   RateLimitConfig        `mapstructure:"rateLimit"`
+	CORS        CORSConfig             `mapstructure:"cors"`
+	Security    SecurityHeadersConfig  `mapstructure:"security"`
+	ServiceAuth ServiceAuthConfig      `mapstructure:"serviceAuth"`
+	Cart        CartConfig             `mapstructure:"cart"`
+	ShareLinks  ShareLinkConfig        `mapstructure:"shareLinks"`
 }
 
 func LoadConfig() *Config {
+	// Production gets strict defaults: no wildcard origins and HSTS enabled.
+	environment := getEnv("APP_ENV", "development")
+	production := environment == "production"
+
+	defaultOrigins := []string{"*"}
+	defaultHSTS := time.Duration(0)
+	if production {
+		defaultOrigins = nil
+		defaultHSTS = 365 * 24 * time.Hour
+	}
+
 	config := &Config{
 		Port:     getEnv("PORT", "8080"),
 		MongoURI: getEnv("MONGO_URI", "mongodb://localhost:27017"),
@@ -70,6 +196,7 @@ func LoadConfig() *Config {
 			Host: getEnv("REGISTRY_HOST", "localhost"),
 		},
 		App: AppConfiguration{
+			Environment: environment,
 			API: APIConfig{
 				Rest: RestConfig{
 					Port: getEnv("PORT", "8080"),
@@ -97,6 +224,77 @@ func LoadConfig() *Config {
 					Encoding: "console",
 				},
 			},
+			Redaction: RedactionConfig{
+				Fields:   getEnvList("LOG_REDACT_FIELDS", ",", nil),
+				Patterns: getEnvList("LOG_REDACT_PATTERNS", ";;", nil),
+			},
+		},
+		Auth: AuthConfig{
+			HMACSecret:          getEnv("JWT_HMAC_SECRET", ""),
+			RSAPublicKey:        getEnv("JWT_RSA_PUBLIC_KEY", ""),
+			ECDSAPublicKey:      getEnv("JWT_ECDSA_PUBLIC_KEY", ""),
+			Issuer:              getEnv("JWT_ISSUER", ""),
+			Audience:            getEnv("JWT_AUDIENCE", ""),
+			RolesClaim:          getEnv("JWT_ROLES_CLAIM", "roles"),
+			Leeway:              getEnvDuration("JWT_LEEWAY", 30*time.Second),
+			JWKS:                getEnv("JWT_JWKS", ""),
+			JWKSRefreshInterval: getEnvDuration("JWT_JWKS_REFRESH_INTERVAL", 15*time.Minute),
+		},
+		ServiceAuth: ServiceAuthConfig{
+			SigningKey: getEnv("SERVICE_TOKEN_KEY", ""),
+			Algorithm:  getEnv("SERVICE_TOKEN_ALG", "HS256"),
+			KeyID:      getEnv("SERVICE_TOKEN_KID", ""),
+			Issuer:     getEnv("SERVICE_TOKEN_ISSUER", "cart-service"),
+			TTL:        getEnvDuration("SERVICE_TOKEN_TTL", 2*time.Minute),
+		},
+		ShareLinks: ShareLinkConfig{
+			SigningKey: getEnv("SHARE_LINK_KEY", ""),
+			Issuer:     getEnv("SHARE_LINK_ISSUER", "cart-service"),
+			DefaultTTL: getEnvDuration("SHARE_LINK_TTL", 7*24*time.Hour),
+			MaxTTL:     getEnvDuration("SHARE_LINK_MAX_TTL", 30*24*time.Hour),
+		},
+		Students: StudentDirectoryConfig{
+			Source:   getEnv("STUDENT_DIRECTORY", "user-service"),
+			FilePath: getEnv("STUDENT_DIRECTORY_FILE", "students.json"),
+			CacheTTL: getEnvDuration("STUDENT_DIRECTORY_CACHE_TTL", 5*time.Minute),
+		},
+		Cart: CartConfig{
+			PurchaseLimitScope: getEnv("PURCHASE_LIMIT_SCOPE", "student"),
+			WeeklyUsageBudget:  getEnvInt("WEEKLY_USAGE_BUDGET", 0),
+			UsagePlanningWeeks: getEnvInt("USAGE_PLANNING_WEEKS", 1),
+			UsageBudgetMode:    getEnv("USAGE_BUDGET_MODE", "warn"),
+			Approval: ApprovalConfig{
+				Enabled:               getEnvBool("APPROVAL_ENABLED", false),
+				StoreTotalThreshold:   getEnvFloat("APPROVAL_STORE_TOTAL_THRESHOLD", 0),
+				ServiceTotalThreshold: getEnvFloat("APPROVAL_SERVICE_TOTAL_THRESHOLD", 0),
+				Categories:            getEnvList("APPROVAL_CATEGORIES", ",", nil),
+			},
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:   getEnvBool("RATE_LIMIT_ENABLED", true),
+			RedisAddr: getEnv(constants.RedisAddr, ""),
+			Default: RateLimit{
+				Rate:  getEnvFloat("RATE_LIMIT_RATE", 5),
+				Burst: getEnvInt("RATE_LIMIT_BURST", 20),
+			},
+			Routes: getEnvRateLimits("RATE_LIMIT_ROUTES", map[string]RateLimit{
+				"POST /api/v1/cart/items":          {Rate: 2, Burst: 10},
+				"POST /api/v1/cart/items/checkout": {Rate: 0.2, Burst: 3},
+			}),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins:   getEnvList("CORS_ALLOWED_ORIGINS", ",", defaultOrigins),
+			AllowedMethods:   getEnvList("CORS_ALLOWED_METHODS", ",", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}),
+			AllowedHeaders:   getEnvList("CORS_ALLOWED_HEADERS", ",", []string{"Authorization", "Content-Type", "X-Request-ID"}),
+			ExposedHeaders:   getEnvList("CORS_EXPOSED_HEADERS", ",", []string{"X-Request-ID", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining"}),
+			AllowCredentials: getEnvBool("CORS_ALLOW_CREDENTIALS", false),
+			MaxAge:           getEnvDuration("CORS_MAX_AGE", 10*time.Minute),
+		},
+		Security: SecurityHeadersConfig{
+			HSTSMaxAge:            getEnvDuration("HSTS_MAX_AGE", defaultHSTS),
+			HSTSIncludeSubdomains: getEnvBool("HSTS_INCLUDE_SUBDOMAINS", production),
+			ContentTypeNosniff:    getEnvBool("CONTENT_TYPE_NOSNIFF", true),
+			ReferrerPolicy:        getEnv("REFERRER_POLICY", "strict-origin-when-cross-origin"),
 		},
 	}
 	return config
@@ -108,3 +306,90 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+func getEnvList(key string, separator string, defaultValue []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists || strings.TrimSpace(value) == "" {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, separator) {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+// getEnvRateLimits - Parses "METHOD /path=rate:burst" entries separated by ";".
+// Entries from the environment are merged over the defaults; malformed entries are ignored.
+func getEnvRateLimits(key string, defaultValue map[string]RateLimit) map[string]RateLimit {
+	limits := make(map[string]RateLimit, len(defaultValue))
+	for route, limit := range defaultValue {
+		limits[route] = limit
+	}
+
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return limits
+	}
+
+	for _, entry := range strings.Split(value, ";") {
+		route, spec, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found {
+			continue
+		}
+		rateValue, burstValue, found := strings.Cut(spec, ":")
+		if !found {
+			continue
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rateValue), 64)
+		if err != nil {
+			continue
+		}
+		burst, err := strconv.Atoi(strings.TrimSpace(burstValue))
+		if err != nil {
+			continue
+		}
+		limits[strings.TrimSpace(route)] = RateLimit{Rate: rate, Burst: burst}
+	}
+
+	return limits
+}
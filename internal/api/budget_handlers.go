@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"store/internal/models"
+	"store/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BudgetHandlers struct {
+	budgetService service.BudgetService
+}
+
+func NewBudgetHandlers(budgetService service.BudgetService) *BudgetHandlers {
+	return &BudgetHandlers{
+		budgetService: budgetService,
+	}
+}
+
+func registerBudgetHandlers(group gin.IRoutes, budgetService service.BudgetService) {
+
+	handlers := NewBudgetHandlers(budgetService)
+
+	group.GET("", handlers.GetBudgets)
+	group.POST("", handlers.CreateBudget)
+	group.PUT("/:budget_id", handlers.UpdateBudget)
+	group.DELETE("/:budget_id", handlers.DeleteBudget)
+}
+
+func (h *BudgetHandlers) GetBudgets(c *gin.Context) {
+
+	budgets, err := h.budgetService.GetBudgets(c.Request.Context(), c.Query("scope"), c.Query("scope_id"))
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Budgets retrieved successfully", budgets)
+}
+
+func (h *BudgetHandlers) CreateBudget(c *gin.Context) {
+
+	req, ok := bindBudgetRequest(c)
+	if !ok {
+		return
+	}
+
+	budget, err := h.budgetService.CreateBudget(c.Request.Context(), req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusCreated, "Budget created successfully", budget)
+}
+
+func (h *BudgetHandlers) UpdateBudget(c *gin.Context) {
+
+	req, ok := bindBudgetRequest(c)
+	if !ok {
+		return
+	}
+
+	budget, err := h.budgetService.UpdateBudget(c.Request.Context(), c.Param("budget_id"), req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Budget updated successfully", budget)
+}
+
+func (h *BudgetHandlers) DeleteBudget(c *gin.Context) {
+
+	err := h.budgetService.DeleteBudget(c.Request.Context(), c.Param("budget_id"))
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Budget deleted successfully", nil)
+}
+
+// bindBudgetRequest - Reads and validates a budget body, recording the admin who sent it.
+func bindBudgetRequest(c *gin.Context) (*models.BudgetRequest, bool) {
+
+	var req models.BudgetRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return nil, false
+	}
+
+	adminID, ok := currentTeacherID(c)
+	if !ok {
+		return nil, false
+	}
+
+	req.AdminID = adminID
+
+	if !validateRequest(c, &req) {
+		return nil, false
+	}
+
+	return &req, true
+}
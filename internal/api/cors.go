@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"store/config"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrWildcardCredentials - Allowing credentials from any origin would let every site make
+// authenticated cross-origin reads, so the combination is refused at startup.
+var ErrWildcardCredentials = errors.New(`CORS_ALLOWED_ORIGINS "*" cannot be combined with CORS_ALLOW_CREDENTIALS`)
+
+// CORS - Answers preflight requests and adds CORS headers for allowed origins.
+// Requests from other origins are passed through without CORS headers, so browsers block them.
+func CORS(cfg config.CORSConfig) (gin.HandlerFunc, error) {
+	if cfg.AllowCredentials {
+		for _, origin := range cfg.AllowedOrigins {
+			if origin == "*" {
+				return nil, ErrWildcardCredentials
+			}
+		}
+	}
+
+	allowedMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	exposedHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+
+		if !originAllowed(cfg.AllowedOrigins, origin) {
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// A literal "*" cannot be combined with credentials, so the origin is always echoed back.
+		c.Header("Access-Control-Allow-Origin", origin)
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			c.Header("Access-Control-Allow-Methods", allowedMethods)
+			c.Header("Access-Control-Allow-Headers", allowedHeaders)
+			if cfg.MaxAge > 0 {
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if exposedHeaders != "" {
+			c.Header("Access-Control-Expose-Headers", exposedHeaders)
+		}
+
+		c.Next()
+	}, nil
+}
+
+// SecurityHeaders - Adds HSTS, X-Content-Type-Options and Referrer-Policy to every response.
+func SecurityHeaders(cfg config.SecurityHeadersConfig) gin.HandlerFunc {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(c *gin.Context) {
+		if hsts != "" {
+			c.Header("Strict-Transport-Security", hsts)
+		}
+		if cfg.ContentTypeNosniff {
+			c.Header("X-Content-Type-Options", "nosniff")
+		}
+		if cfg.ReferrerPolicy != "" {
+			c.Header("Referrer-Policy", cfg.ReferrerPolicy)
+		}
+		c.Next()
+	}
+}
+
+func originAllowed(allowed []string, origin string) bool {
+	for _, candidate := range allowed {
+		switch {
+		case candidate == "*":
+			return true
+		case strings.HasPrefix(candidate, "*."):
+			// "*.example.com" matches "https://app.example.com" but not "https://example.com".
+			if strings.HasSuffix(origin, candidate[1:]) {
+				return true
+			}
+		case strings.EqualFold(candidate, origin):
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"store/config"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORSRefusesWildcardWithCredentials(t *testing.T) {
+	_, err := CORS(config.CORSConfig{AllowedOrigins: []string{"https://app.example.com", "*"}, AllowCredentials: true})
+	if !errors.Is(err, ErrWildcardCredentials) {
+		t.Fatalf("CORS() error = %v, want %v", err, ErrWildcardCredentials)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name            string
+		cfg             config.CORSConfig
+		origin          string
+		wantOrigin      string
+		wantCredentials string
+	}{
+		{
+			name:       "wildcard without credentials",
+			cfg:        config.CORSConfig{AllowedOrigins: []string{"*"}},
+			origin:     "https://evil.example.org",
+			wantOrigin: "https://evil.example.org",
+		},
+		{
+			name:            "exact origin with credentials",
+			cfg:             config.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true},
+			origin:          "https://app.example.com",
+			wantOrigin:      "https://app.example.com",
+			wantCredentials: "true",
+		},
+		{
+			name:            "subdomain pattern",
+			cfg:             config.CORSConfig{AllowedOrigins: []string{"*.example.com"}, AllowCredentials: true},
+			origin:          "https://app.example.com",
+			wantOrigin:      "https://app.example.com",
+			wantCredentials: "true",
+		},
+		{
+			name:   "origin not allowed",
+			cfg:    config.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true},
+			origin: "https://evil.example.org",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cors, err := CORS(tt.cfg)
+			if err != nil {
+				t.Fatalf("CORS: %v", err)
+			}
+
+			router := gin.New()
+			router.Use(cors)
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Origin", tt.origin)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tt.wantCredentials)
+			}
+		})
+	}
+}
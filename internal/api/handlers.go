@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"store/internal/models"
 	"store/internal/service"
+	"store/pkg/auth"
 	"store/pkg/constants"
 
 	"github.com/gin-gonic/gin"
@@ -22,26 +24,77 @@ func NewCartHandlers(cartService service.CartService) *CartHandlers {
 	}
 }
 
-func RegisterHandlers(r *gin.Engine, cartService service.CartService) {
+func RegisterHandlers(r *gin.Engine, cartService service.CartService, wishlistService service.WishlistService, shareLinkService service.ShareLinkService, budgetService service.BudgetService, verifier *auth.Verifier, limiter *RateLimiter) {
 
 	handlers := NewCartHandlers(cartService)
 
-	adminCartGroup := r.Group("/api/v1/admin/cart").Use(Secured())
+	adminCartGroup := r.Group("/api/v1/admin/cart").Use(Secured(verifier), Authorize(constants.RoleAdmin), RequestMetadata(), limiter.Limit())
 	{
 		adminCartGroup.GET("", handlers.GetAllCartGroupedByTeacher)
 		adminCartGroup.GET("/history", handlers.GetCartHistoryByTeacher)
+		adminCartGroup.POST("/transfer", handlers.AdminTransferCarts)
 	}
 
-	cartGroup := r.Group("/api/v1/cart").Use(Secured())
+	approvalGroup := r.Group("/api/v1/approvals").Use(Secured(verifier), Authorize(constants.RoleAdmin, constants.RoleSupervisor), RequestMetadata(), limiter.Limit())
+	{
+		approvalGroup.GET("", handlers.GetCartsForApproval)
+		approvalGroup.POST("/:cart_id/approve", handlers.ApproveCart)
+		approvalGroup.POST("/:cart_id/reject", handlers.RejectCart)
+	}
+
+	cartGroup := r.Group("/api/v1/cart").Use(Secured(verifier), RequestMetadata(), limiter.Limit())
 	{
 		cartGroup.GET("/items", handlers.GetCart)
 		cartGroup.POST("/items", handlers.AddToCart)
+		cartGroup.POST("/items/bulk", handlers.BulkAddToCart)
 		cartGroup.PUT("/items/:product_id", handlers.UpdateQuantity)
 		cartGroup.DELETE("/items/:product_id", handlers.RemoveFromCart)
+		cartGroup.POST("/items/:product_id/save", handlers.SaveForLater)
+		cartGroup.POST("/saved/:product_id/move", handlers.MoveToCart)
 		cartGroup.DELETE("/items", handlers.ClearCart)
 		cartGroup.POST("/items/checkout", handlers.CheckOutCart)
+		cartGroup.POST("/items/copy", handlers.CopyCart)
+		cartGroup.POST("/transfer", handlers.TransferCarts)
+		cartGroup.GET("/carts", handlers.GetNamedCarts)
+		cartGroup.POST("/carts", handlers.CreateNamedCart)
+		cartGroup.PATCH("/carts/:cart_id", handlers.RenameCart)
+		cartGroup.DELETE("/carts/:cart_id", handlers.DeleteCart)
+		cartGroup.POST("/carts/:cart_id/checkout", handlers.CheckOutNamedCart)
+		cartGroup.POST("/submit", handlers.SubmitCart)
+		cartGroup.GET("/members", handlers.GetCartMembers)
+		cartGroup.PUT("/members", handlers.GrantCartAccess)
+		cartGroup.DELETE("/members/:member_id", handlers.RevokeCartAccess)
+		cartGroup.GET("/shared", handlers.GetCartMemberships)
+		cartGroup.GET("/shared/items", handlers.GetSharedCarts)
+	}
+
+	wishlistGroup := r.Group("/api/v1/wishlists").Use(Secured(verifier), RequestMetadata(), limiter.Limit())
+	registerWishlistHandlers(wishlistGroup, wishlistService)
+
+	shareLinkGroup := r.Group("/api/v1/cart/share-links").Use(Secured(verifier), RequestMetadata(), limiter.Limit())
+	sharedCartGroup := r.Group("/api/v1/shared-carts").Use(RequestMetadata(), limiter.Limit())
+	registerShareLinkHandlers(shareLinkGroup, sharedCartGroup, shareLinkService)
+
+	budgetGroup := r.Group("/api/v1/admin/budgets").Use(Secured(verifier), Authorize(constants.RoleAdmin), RequestMetadata(), limiter.Limit())
+	registerBudgetHandlers(budgetGroup, budgetService)
+
+}
+
+// currentTeacherID - Reads the authenticated teacher, answering with 400 when it is missing.
+func currentTeacherID(c *gin.Context) (string, bool) {
+
+	teacherID, exists := c.Get(constants.UserID)
+	if !exists {
+		SendError(c, http.StatusBadRequest, fmt.Errorf("user ID cannot be empty"), models.ErrInvalidRequest)
+		return "", false
+	}
+
+	if teacherID == "" {
+		SendError(c, http.StatusBadRequest, fmt.Errorf("teacher ID cannot be empty"), models.ErrInvalidRequest)
+		return "", false
 	}
 
+	return teacherID.(string), true
 }
 
 func (h *CartHandlers) GetAllCartGroupedByTeacher(c *gin.Context) {
@@ -49,7 +102,7 @@ func (h *CartHandlers) GetAllCartGroupedByTeacher(c *gin.Context) {
 	cart, err := h.cartService.GetAllCartGroupedByTeacher(c.Request.Context())
 
 	if err != nil {
-		SendError(c, http.StatusInternalServerError, err, models.ErrInvalidOperation)
+		SendServiceError(c, err)
 		return
 	}
 
@@ -58,21 +111,15 @@ func (h *CartHandlers) GetAllCartGroupedByTeacher(c *gin.Context) {
 
 func (h *CartHandlers) GetCart(c *gin.Context) {
 
-	teacherID, exists := c.Get(constants.UserID)
-	if !exists {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("user ID cannot be empty"), models.ErrInvalidRequest)
-		return
-	}
-
-	if teacherID == "" {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("teacher ID cannot be empty"), models.ErrInvalidRequest)
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
 		return
 	}
 	
-	cart, err := h.cartService.GetCartByTeacher(c.Request.Context(), teacherID.(string))
+	cart, err := h.cartService.GetCartByTeacher(c.Request.Context(), teacherID)
 
 	if err != nil {
-		SendError(c, http.StatusInternalServerError, err, models.ErrInvalidOperation)
+		SendServiceError(c, err)
 		return
 	}
 
@@ -88,95 +135,125 @@ func (h *CartHandlers) AddToCart(c *gin.Context) {
 		return 
 	}
 
-	teacherID, exists := c.Get(constants.UserID)
-	if !exists {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("user ID cannot be empty"), models.ErrInvalidRequest)
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
 		return
 	}
 
-	if teacherID == "" {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("teacher ID cannot be empty"), models.ErrInvalidRequest)
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
 		return
 	}
 
-	req.TeacherID = teacherID.(string)
+	cartItem, err := h.cartService.AddToCart(c.Request.Context(), &req)
 
-	if req.StudentID == "" {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("student ID cannot be empty"), models.ErrInvalidRequest)
+	if err != nil {
+		SendServiceError(c, err)
 		return
 	}
 
-	if req.ProductID == "" {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("product ID cannot be empty"), models.ErrInvalidRequest)
+	SendSuccess(c, http.StatusOK, "Product has been added to cart", cartItem)
+}
+
+func (h *CartHandlers) UpdateQuantity(c *gin.Context) {
+
+	productID := c.Param("product_id")
+
+	var req models.UpdateCartItemRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
 		return
 	}
 
-	if req.Quantity <= 0 {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("quantity must be greater than 0"), models.ErrInvalidRequest)
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
 		return
 	}
 
-	cartItem, err := h.cartService.AddToCart(c.Request.Context(), &req)
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	err := h.cartService.UpdateQuantityItem(c.Request.Context(), productID, &req)
 
 	if err != nil {
-		SendError(c, http.StatusInternalServerError, err, models.ErrInvalidOperation)
+		SendServiceError(c, err)
 		return
 	}
 
-	SendSuccess(c, http.StatusOK, "Product has been added to cart", cartItem)
+	SendSuccess(c, http.StatusOK, "Update successfully", nil)
+
 }
 
-func (h *CartHandlers) UpdateQuantity(c *gin.Context) {
+func (h *CartHandlers) RemoveFromCart(c *gin.Context) {
 
 	productID := c.Param("product_id")
 
-	var req models.UpdateCartItemRequest
+	var req models.UserRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
 		return
 	}
 
-	teacherID, exists := c.Get(constants.UserID)
-	if !exists {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("user ID cannot be empty"), models.ErrInvalidRequest)
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
 		return
 	}
 
-	if teacherID == "" {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("teacher ID cannot be empty"), models.ErrInvalidRequest)
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
 		return
 	}
 
-	req.TeacherID = teacherID.(string)
+	err := h.cartService.RemoveFromCart(c.Request.Context(), productID, &req)
 
-	if req.StudentID == "" {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("student ID cannot be empty"), models.ErrInvalidRequest)
+	if err != nil {
+		SendServiceError(c, err)
 		return
 	}
 
-	if req.Type != "" && req.Type != "increase" && req.Type != "decrease" {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("type must be 'increase', 'decrease', or empty"), models.ErrInvalidRequest)
+	SendSuccess(c, http.StatusOK, "Product deleted successfully", nil)																														
+}
+
+func (h *CartHandlers) SaveForLater(c *gin.Context) {
+
+	productID := c.Param("product_id")
+
+	var req models.UserRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
 		return
 	}
 
-	if req.Type == "" && *req.Quantity <= 0 {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("quantity must be greater than 0 when type is not specified"), models.ErrInvalidRequest)
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
 		return
 	}
 
-	err := h.cartService.UpdateQuantityItem(c.Request.Context(), productID, &req)
+	req.TeacherID = teacherID
 
-	if err != nil {
-		SendError(c, http.StatusInternalServerError, err, models.ErrInvalidOperation)
+	if !validateRequest(c, &req) {
 		return
 	}
 
-	SendSuccess(c, http.StatusOK, "Update successfully", nil)
+	err := h.cartService.SaveForLater(c.Request.Context(), productID, &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
 
+	SendSuccess(c, http.StatusOK, "Product saved for later", nil)
 }
 
-func (h *CartHandlers) RemoveFromCart(c *gin.Context) {
+func (h *CartHandlers) MoveToCart(c *gin.Context) {
 
 	productID := c.Param("product_id")
 
@@ -187,48 +264,38 @@ func (h *CartHandlers) RemoveFromCart(c *gin.Context) {
 		return
 	}
 
-	teacherID, exists := c.Get(constants.UserID)
-	if !exists {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("user ID cannot be empty"), models.ErrInvalidRequest)
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
 		return
 	}
 
-	if teacherID == "" {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("teacher ID cannot be empty"), models.ErrInvalidRequest)
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
 		return
 	}
 
-	req.TeacherID = teacherID.(string)
-
-	err := h.cartService.RemoveFromCart(c.Request.Context(), req.TeacherID, req.StudentID, productID)
+	cartItem, err := h.cartService.MoveToCart(c.Request.Context(), productID, &req)
 
 	if err != nil {
-		SendError(c, http.StatusInternalServerError, err, models.ErrInvalidOperation)
+		SendServiceError(c, err)
 		return
 	}
 
-	SendSuccess(c, http.StatusOK, "Product deleted successfully", nil)																														
+	SendSuccess(c, http.StatusOK, "Product moved back to cart", cartItem)
 }
 
 func (h *CartHandlers) ClearCart(c *gin.Context) {
 
-	teacherID, exists := c.Get(constants.UserID)
-	if !exists {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("user ID cannot be empty"), models.ErrInvalidRequest)
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
 		return
 	}
 
-	if teacherID == "" {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("teacher ID cannot be empty"), models.ErrInvalidRequest)
-		return
-	}
-
-	TeacherID := teacherID.(string)
-	
-	err := h.cartService.ClearCart(c.Request.Context(), TeacherID)
+	err := h.cartService.ClearCart(c.Request.Context(), teacherID, c.Query("owner_id"))
 
 	if err != nil {
-		SendError(c, http.StatusInternalServerError, err, models.ErrInvalidOperation)
+		SendServiceError(c, err)
 		return
 	}
 
@@ -245,31 +312,21 @@ func (h *CartHandlers) CheckOutCart(c *gin.Context) {
 		return 
 	}
 
-	teacherID, exists := c.Get(constants.UserID)
-	if !exists {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("user ID cannot be empty"), models.ErrInvalidRequest)
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
 		return
 	}
 
-	if teacherID == "" {
-		SendError(c, http.StatusBadRequest, fmt.Errorf("teacher ID cannot be empty"), models.ErrInvalidRequest)
-		return
-	}
+	req.TeacherID = teacherID
 
-	token, ok := c.Get(constants.Token)
-	if !ok {
-		SendError(c, http.StatusForbidden, errors.New("unauthorized"), models.ErrInvalidRequest)
+	if !validateRequest(c, &req) {
 		return
 	}
 
-	ctx := context.WithValue(c, constants.TokenKey, token)
-	
-	req.TeacherID = teacherID.(string)
-
-	err := h.cartService.CheckOutCart(ctx, &req)
+	err := h.cartService.CheckOutCart(c.Request.Context(), &req)
 
 	if err != nil {
-		SendError(c, http.StatusInternalServerError, err, models.ErrInvalidOperation)
+		SendServiceError(c, err)
 		return
 	} 
 
@@ -278,20 +335,455 @@ func (h *CartHandlers) CheckOutCart(c *gin.Context) {
 
 func (h *CartHandlers) GetCartHistoryByTeacher(c *gin.Context) {
 	
-	var req models.UserRequest
+	var req models.TeacherHistoryRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
 		return 
 	}
 
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	var filter models.CartHistoryFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
 
-	cartHistory, err := h.cartService.GetCartHistoryByTeacher(c, req.TeacherID)
+	cartHistory, err := h.cartService.GetCartHistoryByTeacher(c.Request.Context(), req.TeacherID, filter)
 	if err != nil {
-		SendError(c, http.StatusInternalServerError, err, models.ErrInvalidOperation)
+		SendServiceError(c, err)
 		return
 	}
 
 	SendSuccess(c, http.StatusOK, "Cart history data of teacher retrieved successfully", cartHistory)
 
-}
\ No newline at end of file
+}
+
+func (h *CartHandlers) GetNamedCarts(c *gin.Context) {
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	carts, err := h.cartService.GetNamedCarts(c.Request.Context(), teacherID, c.Query("owner_id"), c.Query("student_id"))
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Carts retrieved successfully", carts)
+}
+
+func (h *CartHandlers) CreateNamedCart(c *gin.Context) {
+
+	var req models.CreateCartRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	cart, err := h.cartService.CreateNamedCart(c.Request.Context(), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusCreated, "Cart created successfully", cart)
+}
+
+func (h *CartHandlers) RenameCart(c *gin.Context) {
+
+	var req models.RenameCartRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	cart, err := h.cartService.RenameCart(c.Request.Context(), c.Param("cart_id"), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Cart renamed successfully", cart)
+}
+
+func (h *CartHandlers) DeleteCart(c *gin.Context) {
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	err := h.cartService.DeleteCart(c.Request.Context(), teacherID, c.Query("owner_id"), c.Param("cart_id"))
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Cart deleted successfully", nil)
+}
+
+func (h *CartHandlers) CheckOutNamedCart(c *gin.Context) {
+
+	var req models.CheckOutCartRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	err := h.cartService.CheckOutNamedCart(c.Request.Context(), c.Param("cart_id"), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Checkout successfully", nil)
+}
+
+func (h *CartHandlers) CopyCart(c *gin.Context) {
+
+	var req models.CopyCartRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	result, err := h.cartService.CopyCart(c.Request.Context(), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Cart copied successfully", result)
+}
+
+func (h *CartHandlers) BulkAddToCart(c *gin.Context) {
+
+	var req models.BulkAddToCartRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	result, err := h.cartService.BulkAddToCart(c.Request.Context(), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Bulk add processed", result)
+}
+
+func (h *CartHandlers) GetCartMembers(c *gin.Context) {
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.cartService.GetCartMembers(c.Request.Context(), teacherID)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Cart members retrieved successfully", members)
+}
+
+func (h *CartHandlers) GrantCartAccess(c *gin.Context) {
+
+	var req models.GrantCartAccessRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	member, err := h.cartService.GrantCartAccess(c.Request.Context(), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Cart access granted successfully", member)
+}
+
+func (h *CartHandlers) RevokeCartAccess(c *gin.Context) {
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	err := h.cartService.RevokeCartAccess(c.Request.Context(), teacherID, c.Param("member_id"), c.Query("student_id"))
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Cart access revoked successfully", nil)
+}
+
+// GetCartMemberships - Lists the access other teachers have granted the caller.
+func (h *CartHandlers) GetCartMemberships(c *gin.Context) {
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	memberships, err := h.cartService.GetCartMemberships(c.Request.Context(), teacherID)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Shared cart access retrieved successfully", memberships)
+}
+
+// GetSharedCarts - Lists the carts other teachers share with the caller, apart from the caller's own.
+func (h *CartHandlers) GetSharedCarts(c *gin.Context) {
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	carts, err := h.cartService.GetSharedCarts(c.Request.Context(), teacherID)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Shared carts retrieved successfully", carts)
+}
+
+func (h *CartHandlers) TransferCarts(c *gin.Context) {
+
+	var req models.TransferCartsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	result, err := h.cartService.TransferCarts(c.Request.Context(), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Carts transferred successfully", result)
+}
+
+func (h *CartHandlers) AdminTransferCarts(c *gin.Context) {
+
+	var req models.TransferCartsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	result, err := h.cartService.AdminTransferCarts(c.Request.Context(), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Carts transferred successfully", result)
+}
+
+func (h *CartHandlers) SubmitCart(c *gin.Context) {
+
+	var req models.SubmitCartRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	cart, err := h.cartService.SubmitCart(c.Request.Context(), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Cart submitted for approval successfully", cart)
+}
+
+func (h *CartHandlers) GetCartsForApproval(c *gin.Context) {
+
+	carts, err := h.cartService.GetCartsForApproval(c.Request.Context(), c.Query("status"))
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Carts retrieved successfully", carts)
+}
+
+func (h *CartHandlers) ApproveCart(c *gin.Context) {
+	h.decideCart(c, h.cartService.ApproveCart, "Cart approved successfully")
+}
+
+func (h *CartHandlers) RejectCart(c *gin.Context) {
+	h.decideCart(c, h.cartService.RejectCart, "Cart rejected successfully")
+}
+
+// decideCart - Handles an approver's decision. The body is optional when approving.
+func (h *CartHandlers) decideCart(c *gin.Context, decide func(context.Context, string, *models.CartDecisionRequest) (*models.Cart, error), message string) {
+
+	var req models.CartDecisionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
+
+	approverID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.ApproverID = approverID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	cart, err := decide(c.Request.Context(), c.Param("cart_id"), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, message, cart)
+}
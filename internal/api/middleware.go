@@ -1,38 +1,105 @@
 package api
 
 import (
-	"store/pkg/constants"
+	stdcontext "context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
 	"net/http"
+	"store/internal/models"
+	"store/pkg/auth"
+	"store/pkg/constants"
 	"strings"
+
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-func Secured() gin.HandlerFunc {
+func Secured(verifier *auth.Verifier) gin.HandlerFunc {
 	return func(context *gin.Context) {
 		authorizationHeader := context.GetHeader("Authorization")
 
 		if len(authorizationHeader) == 0 {
-			context.AbortWithStatus(http.StatusForbidden)
+			AbortWithError(context, http.StatusUnauthorized, errors.New("missing authorization header"), models.ErrUnauthorized)
 			return
 		}
-		
+
 		if !strings.HasPrefix(authorizationHeader, "Bearer ") {
-			context.AbortWithStatus(http.StatusUnauthorized)
+			AbortWithError(context, http.StatusUnauthorized, errors.New("authorization header must use the Bearer scheme"), models.ErrUnauthorized)
 			return
 		}
 
-		tokenString := strings.Split(authorizationHeader, " ")[1]
+		tokenString := strings.TrimSpace(strings.TrimPrefix(authorizationHeader, "Bearer "))
 
-		token, _, _ := new(jwt.Parser).ParseUnverified(tokenString, jwt.MapClaims{})
+		claims, err := verifier.Verify(tokenString)
+		if err != nil {
+			// The cause names keys and algorithms, so it is logged rather than returned.
+			log.Printf("Rejected token for %s %s: %v", context.Request.Method, context.Request.URL.Path, err)
+			AbortWithError(context, http.StatusUnauthorized, errors.New("invalid token"), models.ErrUnauthorized)
+			return
+		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			if userId, ok := claims[constants.UserID].(string); ok {
-				context.Set(constants.UserID, userId)
-			}
+		userId, ok := claims[constants.UserID].(string)
+		if !ok || userId == "" {
+			AbortWithError(context, http.StatusUnauthorized, errors.New("token has no user_id claim"), models.ErrUnauthorized)
+			return
 		}
 
+		context.Set(constants.UserID, userId)
+		context.Set(constants.Roles, verifier.Roles(claims))
 		context.Set(constants.Token, tokenString)
+		context.Request = context.Request.WithContext(stdcontext.WithValue(context.Request.Context(), constants.TokenKey, tokenString))
+		context.Next()
+	}
+}
+
+// Authorize - Lets the request through only when the caller holds at least one of the given roles.
+// It must run after Secured, which puts the token's roles into the context.
+func Authorize(roles ...string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		granted := context.GetStringSlice(constants.Roles)
+
+		for _, required := range roles {
+			for _, role := range granted {
+				if role == required {
+					context.Next()
+					return
+				}
+			}
+		}
+
+		AbortWithError(context, http.StatusForbidden, errors.New("insufficient role: requires one of "+strings.Join(roles, ", ")), models.ErrForbidden)
+	}
+}
+
+// RequestMetadata - Captures who is acting and from where, and passes it down through the
+// request context so history records can be attributed. It must run after Secured.
+func RequestMetadata() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		requestID := context.GetHeader("X-Request-ID")
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		context.Set(constants.RequestID, requestID)
+		context.Header("X-Request-ID", requestID)
+
+		actor := &models.Actor{
+			UserID:    context.GetString(constants.UserID),
+			Roles:     context.GetStringSlice(constants.Roles),
+			ClientIP:  context.ClientIP(),
+			UserAgent: context.Request.UserAgent(),
+			RequestID: requestID,
+		}
+
+		context.Request = context.Request.WithContext(models.ContextWithActor(context.Request.Context(), actor))
 		context.Next()
 	}
 }
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
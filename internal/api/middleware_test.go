@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"store/config"
+	"store/internal/models"
+	"store/pkg/auth"
+	"store/pkg/constants"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestSecured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	verifier, err := auth.NewVerifier(config.AuthConfig{HMACSecret: "test-secret", RolesClaim: "roles"})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	sign := func(secret string, claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return token
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+	valid := sign("test-secret", jwt.MapClaims{constants.UserID: "teacher-1", "exp": exp})
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantError     string
+	}{
+		{name: "valid token", authorization: "Bearer " + valid, wantStatus: http.StatusOK},
+		{name: "missing header", wantStatus: http.StatusUnauthorized, wantError: "missing authorization header"},
+		{name: "other scheme", authorization: "Basic dXNlcjpwYXNz", wantStatus: http.StatusUnauthorized, wantError: "authorization header must use the Bearer scheme"},
+		{
+			name:          "wrong signature",
+			authorization: "Bearer " + sign("other-secret", jwt.MapClaims{constants.UserID: "teacher-1", "exp": exp}),
+			wantStatus:    http.StatusUnauthorized,
+			wantError:     "invalid token",
+		},
+		{
+			name:          "expired",
+			authorization: "Bearer " + sign("test-secret", jwt.MapClaims{constants.UserID: "teacher-1", "exp": time.Now().Add(-time.Hour).Unix()}),
+			wantStatus:    http.StatusUnauthorized,
+			wantError:     "invalid token",
+		},
+		{
+			name:          "no user_id claim",
+			authorization: "Bearer " + sign("test-secret", jwt.MapClaims{"exp": exp}),
+			wantStatus:    http.StatusUnauthorized,
+			wantError:     "token has no user_id claim",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/carts", Secured(verifier), func(c *gin.Context) {
+				token, _ := c.Request.Context().Value(constants.TokenKey).(string)
+				c.JSON(http.StatusOK, gin.H{"user_id": c.GetString(constants.UserID), "token": token})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/carts", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				var body map[string]string
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if body["user_id"] != "teacher-1" || body["token"] != valid {
+					t.Errorf("request context = %v, want the caller and its token", body)
+				}
+				return
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.Error != tt.wantError || response.ErrorCode != models.ErrUnauthorized {
+				t.Errorf("response = %q (%s), want %q (%s)", response.Error, response.ErrorCode, tt.wantError, models.ErrUnauthorized)
+			}
+		})
+	}
+}
+
+func TestRequestMetadata(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		requestID     string
+		roles         []string
+		wantRequestID string
+	}{
+		{name: "request ID from the caller", requestID: "req-42", roles: []string{constants.RoleAdmin}, wantRequestID: "req-42"},
+		{name: "generated request ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actor *models.Actor
+
+			router := gin.New()
+			router.GET("/carts", func(c *gin.Context) {
+				c.Set(constants.UserID, "teacher-1")
+				c.Set(constants.Roles, tt.roles)
+			}, RequestMetadata(), func(c *gin.Context) {
+				actor = models.ActorFromContext(c.Request.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/carts", nil)
+			req.RemoteAddr = "10.0.0.7:51234"
+			req.Header.Set("User-Agent", "store-tests")
+			if tt.requestID != "" {
+				req.Header.Set("X-Request-ID", tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if actor == nil {
+				t.Fatal("no actor in the request context")
+			}
+
+			if tt.wantRequestID == "" && len(actor.RequestID) != 32 {
+				t.Errorf("generated request ID = %q, want 32 hex characters", actor.RequestID)
+			}
+			if tt.wantRequestID != "" && actor.RequestID != tt.wantRequestID {
+				t.Errorf("request ID = %q, want %q", actor.RequestID, tt.wantRequestID)
+			}
+			if got := rec.Header().Get("X-Request-ID"); got != actor.RequestID {
+				t.Errorf("X-Request-ID response header = %q, want %q", got, actor.RequestID)
+			}
+
+			want := models.Actor{UserID: "teacher-1", Roles: tt.roles, ClientIP: "10.0.0.7", UserAgent: "store-tests", RequestID: actor.RequestID}
+			if !reflect.DeepEqual(*actor, want) {
+				t.Errorf("actor = %+v, want %+v", *actor, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"store/config"
+	"store/internal/models"
+	"store/pkg/constants"
+	"store/pkg/ratelimit"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimiter - Throttles requests per user and per route with token buckets.
+type RateLimiter struct {
+	store ratelimit.Store
+	cfg   config.RateLimitConfig
+}
+
+func NewRateLimiter(store ratelimit.Store, cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+// Limit - Takes one token from the caller's bucket for the matched route. It must run after
+// Secured so the bucket can be keyed by user ID. Store failures let the request through.
+func (l *RateLimiter) Limit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil || !l.cfg.Enabled {
+			c.Next()
+			return
+		}
+
+		route := c.Request.Method + " " + c.FullPath()
+
+		limit := l.cfg.Default
+		if routeLimit, ok := l.cfg.Routes[route]; ok {
+			limit = routeLimit
+		}
+
+		userID := c.GetString(constants.UserID)
+		if userID == "" {
+			userID = "ip:" + c.ClientIP()
+		}
+
+		result, err := l.store.Allow(c.Request.Context(), userID+"|"+route, ratelimit.Limit{
+			Rate:  limit.Rate,
+			Burst: limit.Burst,
+		})
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			AbortWithError(c, http.StatusTooManyRequests, errors.New("rate limit exceeded, retry after "+strconv.Itoa(retryAfter)+"s"), models.ErrRateLimited)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"errors"
+	"net/http"
 	"store/internal/models"
 
 	"github.com/gin-gonic/gin"
@@ -20,4 +22,29 @@ func SendError( c* gin.Context, statusCode int, err error, errorCode string) {
 		Error: err.Error(),
 		ErrorCode: errorCode,
 	})
-}
\ No newline at end of file
+}
+
+func AbortWithError(c *gin.Context, statusCode int, err error, errorCode string) {
+	c.AbortWithStatusJSON(statusCode, models.APIResponse{
+		StatusCode: statusCode,
+		Error:      err.Error(),
+		ErrorCode:  errorCode,
+	})
+}
+
+// SendServiceError - Reports an error returned by the service layer. Errors that carry their
+// own status and code are sent as-is, anything else is treated as a failed operation.
+func SendServiceError(c *gin.Context, err error) {
+	var serviceErr *models.ServiceError
+	if errors.As(err, &serviceErr) {
+		c.JSON(serviceErr.StatusCode, models.APIResponse{
+			StatusCode: serviceErr.StatusCode,
+			Error:      serviceErr.Message,
+			ErrorCode:  serviceErr.ErrorCode,
+			Details:    serviceErr.Details,
+		})
+		return
+	}
+
+	SendError(c, http.StatusInternalServerError, err, models.ErrInvalidOperation)
+}
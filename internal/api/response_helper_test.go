@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"store/internal/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSendServiceError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantError  string
+		wantCode   string
+	}{
+		{
+			name:       "service error",
+			err:        models.NewServiceError(http.StatusConflict, models.ErrCartModified, "order 202405011234 conflicts with cart 0912345678", nil),
+			wantStatus: http.StatusConflict,
+			wantError:  "order 202405011234 conflicts with cart 0912345678",
+			wantCode:   models.ErrCartModified,
+		},
+		{
+			name:       "wrapped service error",
+			err:        fmt.Errorf("failed to save cart: %w", models.NewServiceError(http.StatusNotFound, models.ErrCartNotFound, "cart not found", nil)),
+			wantStatus: http.StatusNotFound,
+			wantError:  "cart not found",
+			wantCode:   models.ErrCartNotFound,
+		},
+		{
+			name:       "other error",
+			err:        errors.New("connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantError:  "connection refused",
+			wantCode:   models.ErrInvalidOperation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+
+			SendServiceError(c, tt.err)
+
+			var response models.APIResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if rec.Code != tt.wantStatus || response.Error != tt.wantError || response.ErrorCode != tt.wantCode {
+				t.Errorf("response = %d %q (%s), want %d %q (%s)", rec.Code, response.Error, response.ErrorCode, tt.wantStatus, tt.wantError, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"store/internal/models"
+	"store/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShareLinkHandlers struct {
+	shareLinkService service.ShareLinkService
+}
+
+func NewShareLinkHandlers(shareLinkService service.ShareLinkService) *ShareLinkHandlers {
+	return &ShareLinkHandlers{
+		shareLinkService: shareLinkService,
+	}
+}
+
+// registerShareLinkHandlers - Link management goes on the authenticated group; the shared cart itself
+// is served on the public group.
+func registerShareLinkHandlers(group gin.IRoutes, public gin.IRoutes, shareLinkService service.ShareLinkService) {
+
+	handlers := NewShareLinkHandlers(shareLinkService)
+
+	group.GET("", handlers.GetShareLinks)
+	group.POST("", handlers.CreateShareLink)
+	group.DELETE("/:link_id", handlers.RevokeShareLink)
+
+	public.GET("/:token", handlers.GetSharedCart)
+}
+
+func (h *ShareLinkHandlers) GetShareLinks(c *gin.Context) {
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	links, err := h.shareLinkService.GetShareLinks(c.Request.Context(), teacherID, c.Query("owner_id"), c.Query("student_id"))
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Share links retrieved successfully", links)
+}
+
+func (h *ShareLinkHandlers) CreateShareLink(c *gin.Context) {
+
+	var req models.CreateShareLinkRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	link, err := h.shareLinkService.CreateShareLink(c.Request.Context(), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusCreated, "Share link created successfully", link)
+}
+
+func (h *ShareLinkHandlers) RevokeShareLink(c *gin.Context) {
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	err := h.shareLinkService.RevokeShareLink(c.Request.Context(), teacherID, c.Param("link_id"))
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Share link revoked successfully", nil)
+}
+
+// GetSharedCart - Public, read-only view of a shared cart.
+func (h *ShareLinkHandlers) GetSharedCart(c *gin.Context) {
+
+	cart, err := h.shareLinkService.GetSharedCart(c.Request.Context(), c.Param("token"))
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Shared cart retrieved successfully", cart)
+}
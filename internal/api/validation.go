@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"store/internal/models"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by their JSON name so clients can map errors back to the payload.
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	return v
+}
+
+// validateRequest - Enforces the `validate` struct tags of req. When any rule fails it sends a 422
+// listing every failing field and returns false.
+func validateRequest(c *gin.Context, req interface{}) bool {
+	err := validate.Struct(req)
+	if err == nil {
+		return true
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		SendError(c, http.StatusUnprocessableEntity, err, models.ErrValidation)
+		return false
+	}
+
+	fields := make([]models.FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		fields = append(fields, models.FieldError{
+			Field:   fieldPath(fieldErr),
+			Rule:    fieldErr.Tag(),
+			Param:   fieldErr.Param(),
+			Message: fieldMessage(fieldErr),
+		})
+	}
+
+	c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+		StatusCode: http.StatusUnprocessableEntity,
+		Error:      "request validation failed",
+		ErrorCode:  models.ErrValidation,
+		Details:    fields,
+	})
+	return false
+}
+
+// fieldPath - Drops the top-level struct name from the namespace, e.g. "AddToCartRequest.quantity" -> "quantity".
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func fieldMessage(fieldErr validator.FieldError) string {
+	field := fieldPath(fieldErr)
+
+	switch fieldErr.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s]", field, fieldErr.Param())
+	case "min":
+		if fieldErr.Kind() == reflect.String || fieldErr.Kind() == reflect.Slice {
+			return fmt.Sprintf("%s must contain at least %s", field, fieldErr.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", field, fieldErr.Param())
+	case "max":
+		if fieldErr.Kind() == reflect.String || fieldErr.Kind() == reflect.Slice {
+			return fmt.Sprintf("%s must contain at most %s", field, fieldErr.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s", field, fieldErr.Param())
+	default:
+		return fmt.Sprintf("%s failed the %s rule", field, fieldErr.Tag())
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"store/internal/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestValidateRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	one := 1
+	zero := 0
+
+	tests := []struct {
+		name       string
+		req        interface{}
+		wantFields []models.FieldError
+	}{
+		{
+			name: "valid update",
+			req:  &models.UpdateCartItemRequest{Quantity: &one, Type: "increase", TeacherID: "t1", StudentID: "s1"},
+		},
+		{
+			name: "update without types",
+			req:  &models.UpdateCartItemRequest{Quantity: &one, TeacherID: "t1", StudentID: "s1"},
+			wantFields: []models.FieldError{
+				{Field: "types", Rule: "required", Message: "types is required"},
+			},
+		},
+		{
+			name: "update without quantity",
+			req:  &models.UpdateCartItemRequest{Type: "decrease", TeacherID: "t1", StudentID: "s1"},
+			wantFields: []models.FieldError{
+				{Field: "quantity", Rule: "required", Message: "quantity is required"},
+			},
+		},
+		{
+			name: "update with every field wrong",
+			req:  &models.UpdateCartItemRequest{Quantity: &zero, Type: "double"},
+			wantFields: []models.FieldError{
+				{Field: "quantity", Rule: "min", Param: "1", Message: "quantity must be at least 1"},
+				{Field: "types", Rule: "oneof", Param: "increase decrease", Message: "types must be one of [increase decrease]"},
+				{Field: "teacher_id", Rule: "required", Message: "teacher_id is required"},
+				{Field: "student_id", Rule: "required", Message: "student_id is required"},
+			},
+		},
+		{
+			name: "nested variation",
+			req: &models.AddToCartRequest{
+				ProductID: "p1", TeacherID: "t1", StudentID: "s1", Quantity: 1,
+				Variation: &models.VariationSelection{VariationName: "Size"},
+			},
+			wantFields: []models.FieldError{
+				{Field: "variation.option", Rule: "required", Message: "variation.option is required"},
+			},
+		},
+		{
+			name: "checkout",
+			req: &models.CheckOutCartRequest{
+				TeacherID: "t1", Email: "not-an-email", Types: "cod", Street: "1 Main St", City: "Hanoi", Country: "VN",
+			},
+			wantFields: []models.FieldError{
+				{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+				{Field: "phone", Rule: "required", Message: "phone is required"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+
+			ok := validateRequest(c, tt.req)
+			if ok != (tt.wantFields == nil) {
+				t.Fatalf("validateRequest() = %v, want %v", ok, tt.wantFields == nil)
+			}
+			if ok {
+				if rec.Body.Len() != 0 {
+					t.Errorf("valid request wrote a response: %s", rec.Body.String())
+				}
+				return
+			}
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+			}
+
+			var response struct {
+				ErrorCode string              `json:"error_code"`
+				Details   []models.FieldError `json:"details"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.ErrorCode != models.ErrValidation {
+				t.Errorf("error_code = %q, want %q", response.ErrorCode, models.ErrValidation)
+			}
+			if !reflect.DeepEqual(response.Details, tt.wantFields) {
+				t.Errorf("details = %+v, want %+v", response.Details, tt.wantFields)
+			}
+		})
+	}
+}
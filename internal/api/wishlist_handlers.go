@@ -0,0 +1,206 @@
+package api
+
+import (
+	"net/http"
+	"store/internal/models"
+	"store/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WishlistHandlers struct {
+	wishlistService service.WishlistService
+}
+
+func NewWishlistHandlers(wishlistService service.WishlistService) *WishlistHandlers {
+	return &WishlistHandlers{
+		wishlistService: wishlistService,
+	}
+}
+
+func registerWishlistHandlers(group gin.IRoutes, wishlistService service.WishlistService) {
+
+	handlers := NewWishlistHandlers(wishlistService)
+
+	group.GET("", handlers.GetWishlists)
+	group.POST("", handlers.CreateWishlist)
+	group.GET("/:wishlist_id", handlers.GetWishlist)
+	group.DELETE("/:wishlist_id", handlers.DeleteWishlist)
+	group.POST("/:wishlist_id/items", handlers.AddItem)
+	group.DELETE("/:wishlist_id/items/:product_id", handlers.RemoveItem)
+	group.POST("/:wishlist_id/items/:product_id/move", handlers.MoveToCart)
+}
+
+func (h *WishlistHandlers) GetWishlists(c *gin.Context) {
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	wishlists, err := h.wishlistService.GetWishlists(c.Request.Context(), teacherID)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Wishlists retrieved successfully", wishlists)
+}
+
+func (h *WishlistHandlers) CreateWishlist(c *gin.Context) {
+
+	var req models.CreateWishlistRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	wishlist, err := h.wishlistService.CreateWishlist(c.Request.Context(), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusCreated, "Wishlist created successfully", wishlist)
+}
+
+func (h *WishlistHandlers) GetWishlist(c *gin.Context) {
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.wishlistService.GetWishlist(c.Request.Context(), teacherID, c.Param("wishlist_id"))
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Wishlist retrieved successfully", report)
+}
+
+func (h *WishlistHandlers) DeleteWishlist(c *gin.Context) {
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	err := h.wishlistService.DeleteWishlist(c.Request.Context(), teacherID, c.Param("wishlist_id"))
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Wishlist deleted successfully", nil)
+}
+
+func (h *WishlistHandlers) AddItem(c *gin.Context) {
+
+	var req models.AddWishlistItemRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	entry, err := h.wishlistService.AddItem(c.Request.Context(), c.Param("wishlist_id"), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Product has been added to wishlist", entry)
+}
+
+func (h *WishlistHandlers) RemoveItem(c *gin.Context) {
+
+	var req models.WishlistItemRequest
+
+	// The body is optional; it only carries the variation of the entry to remove.
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+			return
+		}
+	}
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	err := h.wishlistService.RemoveItem(c.Request.Context(), c.Param("wishlist_id"), c.Param("product_id"), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Product removed from wishlist", nil)
+}
+
+func (h *WishlistHandlers) MoveToCart(c *gin.Context) {
+
+	var req models.MoveWishlistItemRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendError(c, http.StatusBadRequest, err, models.ErrInvalidRequest)
+		return
+	}
+
+	teacherID, ok := currentTeacherID(c)
+	if !ok {
+		return
+	}
+
+	req.TeacherID = teacherID
+
+	if !validateRequest(c, &req) {
+		return
+	}
+
+	cartItem, err := h.wishlistService.MoveToCart(c.Request.Context(), c.Param("wishlist_id"), c.Param("product_id"), &req)
+
+	if err != nil {
+		SendServiceError(c, err)
+		return
+	}
+
+	SendSuccess(c, http.StatusOK, "Product has been moved to cart", cartItem)
+}
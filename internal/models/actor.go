@@ -0,0 +1,25 @@
+package models
+
+import (
+	"context"
+	"store/pkg/constants"
+)
+
+// Actor - The user behind a cart change and the request it came from.
+type Actor struct {
+	UserID    string   `bson:"user_id" json:"user_id"`
+	Roles     []string `bson:"roles,omitempty" json:"roles,omitempty"`
+	ClientIP  string   `bson:"client_ip" json:"client_ip"`
+	UserAgent string   `bson:"user_agent" json:"user_agent"`
+	RequestID string   `bson:"request_id" json:"request_id"`
+}
+
+func ContextWithActor(ctx context.Context, actor *Actor) context.Context {
+	return context.WithValue(ctx, constants.ActorKey, actor)
+}
+
+// ActorFromContext - Returns the actor captured by the request middleware, or nil outside a request.
+func ActorFromContext(ctx context.Context) *Actor {
+	actor, _ := ctx.Value(constants.ActorKey).(*Actor)
+	return actor
+}
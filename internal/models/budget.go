@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Budget scopes: a student budget caps the spending on one student, whichever teacher orders for
+// them; a teacher budget caps everything one teacher orders.
+const (
+	BudgetScopeStudent = "student"
+	BudgetScopeTeacher = "teacher"
+)
+
+// Budget modes: "reject" refuses changes and checkouts that go past the budget, "warn" only flags
+// the carts.
+const (
+	BudgetModeWarn   = "warn"
+	BudgetModeReject = "reject"
+)
+
+// WarningBudget - Warning code for a cart that takes spending past a budget in warn mode.
+const WarningBudget = "BUDGET_EXCEEDED"
+
+// Budget - A spending cap for a term, from StartsAt up to EndsAt. ScopeID is the student or teacher
+// the budget belongs to. A zero limit leaves that total uncapped.
+type Budget struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Scope        string             `bson:"scope" json:"scope"`
+	ScopeID      string             `bson:"scope_id" json:"scope_id"`
+	Term         string             `bson:"term" json:"term"`
+	StartsAt     time.Time          `bson:"starts_at" json:"starts_at"`
+	EndsAt       time.Time          `bson:"ends_at" json:"ends_at"`
+	LimitStore   float64            `bson:"limit_store" json:"limit_store"`
+	LimitService float64            `bson:"limit_service" json:"limit_service"`
+	Mode         string             `bson:"mode" json:"mode"`
+	CreatedBy    string             `bson:"created_by" json:"created_by"`
+	CreateAt     time.Time          `bson:"create_at" json:"create_at"`
+	UpdateAt     time.Time          `bson:"update_at" json:"update_at"`
+}
+
+// BudgetStatus - How much of a budget is used. Spent is what was ordered during the term and InCart
+// what the carts in question hold; Remaining is what is left once they are ordered too, and is
+// negative when they go past the limit. Remaining is absent for an uncapped total.
+type BudgetStatus struct {
+	BudgetID         string   `json:"budget_id"`
+	Scope            string   `json:"scope"`
+	ScopeID          string   `json:"scope_id"`
+	Term             string   `json:"term"`
+	Mode             string   `json:"mode"`
+	LimitStore       float64  `json:"limit_store"`
+	LimitService     float64  `json:"limit_service"`
+	SpentStore       float64  `json:"spent_store"`
+	SpentService     float64  `json:"spent_service"`
+	InCartStore      float64  `json:"in_cart_store"`
+	InCartService    float64  `json:"in_cart_service"`
+	RemainingStore   *float64 `json:"remaining_store,omitempty"`
+	RemainingService *float64 `json:"remaining_service,omitempty"`
+}
+
+// NewBudgetStatus - Works out the remaining amounts of the budget.
+func NewBudgetStatus(budget Budget, spentStore float64, spentService float64, inCartStore float64, inCartService float64) BudgetStatus {
+	status := BudgetStatus{
+		BudgetID:      budget.ID.Hex(),
+		Scope:         budget.Scope,
+		ScopeID:       budget.ScopeID,
+		Term:          budget.Term,
+		Mode:          budget.Mode,
+		LimitStore:    budget.LimitStore,
+		LimitService:  budget.LimitService,
+		SpentStore:    RoundPrice(spentStore),
+		SpentService:  RoundPrice(spentService),
+		InCartStore:   RoundPrice(inCartStore),
+		InCartService: RoundPrice(inCartService),
+	}
+
+	if budget.LimitStore > 0 {
+		remaining := RoundPrice(budget.LimitStore - spentStore - inCartStore)
+		status.RemainingStore = &remaining
+	}
+	if budget.LimitService > 0 {
+		remaining := RoundPrice(budget.LimitService - spentService - inCartService)
+		status.RemainingService = &remaining
+	}
+
+	return status
+}
+
+// Exceeded - Reports whether the carts go past either limit.
+func (s BudgetStatus) Exceeded() bool {
+	return (s.RemainingStore != nil && *s.RemainingStore < 0) || (s.RemainingService != nil && *s.RemainingService < 0)
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestNewBudgetStatus(t *testing.T) {
+	tests := []struct {
+		name                                     string
+		budget                                   Budget
+		spentStore, spentService                 float64
+		inCartStore, inCartService               float64
+		wantRemainingStore, wantRemainingService *float64
+		wantExceeded                             bool
+	}{
+		{
+			name:                 "within both limits",
+			budget:               Budget{LimitStore: 500, LimitService: 100},
+			spentStore:           200.1,
+			spentService:         40,
+			inCartStore:          99.95,
+			inCartService:        10,
+			wantRemainingStore:   floatPointer(199.95),
+			wantRemainingService: floatPointer(50),
+			wantExceeded:         false,
+		},
+		{
+			name:               "exactly at the limit",
+			budget:             Budget{LimitStore: 300},
+			spentStore:         200,
+			inCartStore:        100,
+			wantRemainingStore: floatPointer(0),
+		},
+		{
+			name:                 "store limit exceeded",
+			budget:               Budget{LimitStore: 300, LimitService: 100},
+			spentStore:           250,
+			inCartStore:          60.5,
+			wantRemainingStore:   floatPointer(-10.5),
+			wantRemainingService: floatPointer(100),
+			wantExceeded:         true,
+		},
+		{
+			name:                 "service limit exceeded by the cart alone",
+			budget:               Budget{LimitService: 50},
+			inCartService:        50.01,
+			wantRemainingService: floatPointer(-0.01),
+			wantExceeded:         true,
+		},
+		{
+			name:          "uncapped totals",
+			budget:        Budget{},
+			spentStore:    10000,
+			inCartService: 10000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := NewBudgetStatus(tt.budget, tt.spentStore, tt.spentService, tt.inCartStore, tt.inCartService)
+
+			if !equalFloatPointer(status.RemainingStore, tt.wantRemainingStore) {
+				t.Errorf("RemainingStore = %v, want %v", describeFloatPointer(status.RemainingStore), describeFloatPointer(tt.wantRemainingStore))
+			}
+			if !equalFloatPointer(status.RemainingService, tt.wantRemainingService) {
+				t.Errorf("RemainingService = %v, want %v", describeFloatPointer(status.RemainingService), describeFloatPointer(tt.wantRemainingService))
+			}
+			if got := status.Exceeded(); got != tt.wantExceeded {
+				t.Errorf("Exceeded() = %v, want %v", got, tt.wantExceeded)
+			}
+		})
+	}
+}
+
+func TestNewBudgetStatusRoundsAmounts(t *testing.T) {
+	status := NewBudgetStatus(Budget{Scope: BudgetScopeStudent, ScopeID: "student-1", Term: "Fall", Mode: BudgetModeWarn}, 0.1+0.2, 1.005000001, 10.999, 0)
+
+	if status.SpentStore != 0.3 || status.SpentService != 1.01 || status.InCartStore != 11 {
+		t.Errorf("amounts = %v, %v, %v, want 0.3, 1.01, 11", status.SpentStore, status.SpentService, status.InCartStore)
+	}
+	if status.Scope != BudgetScopeStudent || status.ScopeID != "student-1" || status.Term != "Fall" || status.Mode != BudgetModeWarn {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+func floatPointer(value float64) *float64 {
+	return &value
+}
+
+func equalFloatPointer(a *float64, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func describeFloatPointer(value *float64) interface{} {
+	if value == nil {
+		return "uncapped"
+	}
+	return *value
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Cart statuses. A cart is a draft until it is submitted for approval; an approver then approves or
+// rejects it, and checking it out marks it ordered. Changing the lines of a cart returns it to draft.
+// Carts stored before the workflow existed have no status and are drafts.
+const (
+	CartStatusDraft     = "draft"
+	CartStatusSubmitted = "submitted"
+	CartStatusApproved  = "approved"
+	CartStatusRejected  = "rejected"
+	CartStatusOrdered   = "ordered"
+)
+
+// ValidCartStatus - Reports whether status is one of the cart statuses.
+func ValidCartStatus(status string) bool {
+	switch status {
+	case CartStatusDraft, CartStatusSubmitted, CartStatusApproved, CartStatusRejected, CartStatusOrdered:
+		return true
+	}
+	return false
+}
+
+// CartApproval - The latest submission of a cart and the decision on it. Reasons are the approval
+// rules the cart matched when it was submitted.
+type CartApproval struct {
+	Reasons     []string   `bson:"reasons" json:"reasons"`
+	SubmittedBy string     `bson:"submitted_by" json:"submitted_by"`
+	SubmittedAt time.Time  `bson:"submitted_at" json:"submitted_at"`
+	DecidedBy   string     `bson:"decided_by,omitempty" json:"decided_by,omitempty"`
+	DecidedAt   *time.Time `bson:"decided_at,omitempty" json:"decided_at,omitempty"`
+	Comment     string     `bson:"comment,omitempty" json:"comment,omitempty"`
+}
+
+// CurrentStatus - The cart's status, treating a missing one as draft.
+func (c *Cart) CurrentStatus() string {
+	if c.Status == "" {
+		return CartStatusDraft
+	}
+	return c.Status
+}
@@ -0,0 +1,24 @@
+package models
+
+// BulkAddResult - The outcome of a bulk add, with one entry per request line in request order.
+// Applied is false when nothing was added, as when an atomic request was refused.
+type BulkAddResult struct {
+	Atomic    bool             `json:"atomic"`
+	Applied   bool             `json:"applied"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Lines     []BulkLineResult `json:"lines"`
+}
+
+// BulkLineResult - Status is "added", "failed", or "not_applied" for a line that passed its checks
+// in an atomic request that was refused because of other lines.
+type BulkLineResult struct {
+	Index     int         `json:"index"`
+	StudentID string      `json:"student_id"`
+	ProductID string      `json:"product_id"`
+	Status    string      `json:"status"`
+	Item      *CartItem   `json:"item,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	ErrorCode string      `json:"error_code,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+}
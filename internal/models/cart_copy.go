@@ -0,0 +1,27 @@
+package models
+
+// CopyCartResult - The outcome of copying a cart: one entry per target student, and the source
+// lines that could not be copied to anyone.
+type CopyCartResult struct {
+	Targets []CopyTargetResult `json:"targets"`
+	Skipped []CopySkippedLine  `json:"skipped,omitempty"`
+}
+
+// CopyTargetResult - Status is "copied" or "failed". A failed target is left unchanged.
+type CopyTargetResult struct {
+	StudentID string      `json:"student_id"`
+	Status    string      `json:"status"`
+	Items     int         `json:"items,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	ErrorCode string      `json:"error_code,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+}
+
+// CopySkippedLine - A source line left out of the copy. Reason is "product_not_found" or
+// "invalid_variation".
+type CopySkippedLine struct {
+	ProductID   string              `json:"product_id"`
+	ProductName string              `json:"product_name"`
+	Variation   *VariationSelection `json:"variation,omitempty"`
+	Reason      string              `json:"reason"`
+}
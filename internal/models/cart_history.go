@@ -7,11 +7,30 @@ import (
 )
 
 type CartHistory struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	TeacherID  string             `bson:"teacher_id" json:"teacher_id"`
-	StudentID  string             `bson:"student_id" json:"student_id"`
-	ProductID  primitive.ObjectID `bson:"product_id" json:"product_id"`
-	EventType  string             `bson:"event_type" json:"event_type"`
-	Quantity   int                `bson:"quantity" json:"quantity"`
-	OcccuredOn time.Time          `bson:"occcured_on" json:"occcured_on"`
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	TeacherID string              `bson:"teacher_id" json:"teacher_id"`
+	StudentID string              `bson:"student_id" json:"student_id"`
+	ProductID primitive.ObjectID  `bson:"product_id,omitempty" json:"product_id"`
+	Variation *VariationSelection `bson:"variation,omitempty" json:"variation,omitempty"`
+	EventType string              `bson:"event_type" json:"event_type"`
+	Quantity  int                 `bson:"quantity" json:"quantity"`
+	// PriceStore and PriceService are the line's unit prices at the time. Events recorded before
+	// prices were kept have none, and count as zero towards budgets.
+	PriceStore   float64   `bson:"price_store,omitempty" json:"price_store,omitempty"`
+	PriceService float64   `bson:"price_service,omitempty" json:"price_service,omitempty"`
+	OcccuredOn   time.Time `bson:"occcured_on" json:"occcured_on"`
+	Actor        *Actor    `bson:"actor,omitempty" json:"actor,omitempty"`
+	// CartID and Comment are set on approval workflow events, which concern a whole cart rather
+	// than one line; those events have no product.
+	CartID  *primitive.ObjectID `bson:"cart_id,omitempty" json:"cart_id,omitempty"`
+	Comment string              `bson:"comment,omitempty" json:"comment,omitempty"`
+}
+
+// CartHistoryFilter - Optional admin filters on who made a change and from where.
+type CartHistoryFilter struct {
+	ActorID   string `form:"actor_id"`
+	ActorRole string `form:"actor_role"`
+	ClientIP  string `form:"client_ip"`
+	UserAgent string `form:"user_agent"`
+	RequestID string `form:"request_id"`
 }
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cart permissions a teacher can grant on their carts. Each one includes the ones before it.
+const (
+	CartPermissionView     = "view"
+	CartPermissionEdit     = "edit"
+	CartPermissionCheckout = "checkout"
+)
+
+var cartPermissionRank = map[string]int{
+	CartPermissionView:     1,
+	CartPermissionEdit:     2,
+	CartPermissionCheckout: 3,
+}
+
+// CartMember - Access granted by the owner of a set of carts to another teacher, such as a
+// co-teacher or substitute. An empty StudentID covers all of the owner's students.
+type CartMember struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	OwnerID    string             `bson:"owner_id" json:"owner_id"`
+	MemberID   string             `bson:"member_id" json:"member_id"`
+	StudentID  string             `bson:"student_id" json:"student_id,omitempty"`
+	Permission string             `bson:"permission" json:"permission"`
+	CreateAt   time.Time          `bson:"create_at" json:"create_at"`
+	UpdateAt   time.Time          `bson:"update_at" json:"update_at"`
+}
+
+// Covers - Reports whether the grant applies to the student's carts, or to all of the owner's carts
+// when studentID is empty.
+func (m CartMember) Covers(studentID string) bool {
+	return m.StudentID == "" || m.StudentID == studentID
+}
+
+// Allows - Reports whether the grant includes the permission.
+func (m CartMember) Allows(permission string) bool {
+	return cartPermissionRank[m.Permission] >= cartPermissionRank[permission]
+}
@@ -0,0 +1,22 @@
+package models
+
+// TransferResult - The outcome of a cart transfer, with one entry per student.
+type TransferResult struct {
+	FromTeacherID string                  `json:"from_teacher_id"`
+	ToTeacherID   string                  `json:"to_teacher_id"`
+	Students      []TransferStudentResult `json:"students"`
+}
+
+// TransferStudentResult - Status is "transferred", "skipped" when the student has no carts with the
+// source teacher, or "failed", in which case carts not yet moved stay with the source teacher.
+// Conflicts counts the lines that were already in the target default cart.
+type TransferStudentResult struct {
+	StudentID  string      `json:"student_id"`
+	Status     string      `json:"status"`
+	Items      int         `json:"items,omitempty"`
+	NamedCarts int         `json:"named_carts,omitempty"`
+	Conflicts  int         `json:"conflicts,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	ErrorCode  string      `json:"error_code,omitempty"`
+	Details    interface{} `json:"details,omitempty"`
+}
@@ -1,29 +1,238 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// VariationSelection - The variation option picked for a product, e.g. Size "M".
+type VariationSelection struct {
+	VariationName string `bson:"variation_name" json:"variation_name" validate:"required"`
+	Option        string `bson:"option" json:"option" validate:"required"`
+}
+
+// Equal - Two selections are equal when both are absent or both pick the same option.
+func (v *VariationSelection) Equal(other *VariationSelection) bool {
+	if v == nil || other == nil {
+		return v == nil && other == nil
+	}
+	return v.VariationName == other.VariationName && v.Option == other.Option
+}
+
+// UsageConfig - How a product is meant to be used in lessons. Usage times are in minutes per use.
+type UsageConfig struct {
+	NumberOfUses     int `bson:"number_of_uses" json:"number_of_uses"`
+	MinimumUsageTime int `bson:"minimum_usage_time" json:"minimum_usage_time"`
+	MaximumUsageTime int `bson:"maximum_usage_time" json:"maximum_usage_time"`
+}
+
+// CartWarning - A non-blocking notice about a cart, e.g. that it goes past the weekly usage budget.
+type CartWarning struct {
+	Code    string `bson:"code" json:"code"`
+	Message string `bson:"message" json:"message"`
+}
+
+// WarningUsageBudget - Warning code for a cart whose planned minimum usage time per week exceeds the weekly budget.
+const WarningUsageBudget = "USAGE_BUDGET_EXCEEDED"
+
 type CartItem struct {
-	ProductID    primitive.ObjectID `bson:"product_id" json:"product_id"`
-	ProductName  string             `bson:"product_name" json:"product_name"`
-	TopicName    string             `bson:"topic_name" json:"topic_name"`
-	CategoryName string             `bson:"category_name" json:"category_name"`
-	PriceStore   float64            `bson:"price_store" json:"price_store"`
-	PriceService float64            `bson:"price_service" json:"price_service"`
-	Quantity     int                `bson:"quantity" json:"quantity"`
-	ImageURL     string             `bson:"image_url" json:"image_url"`
+	ProductID    primitive.ObjectID  `bson:"product_id" json:"product_id"`
+	Variation    *VariationSelection `bson:"variation,omitempty" json:"variation,omitempty"`
+	ProductName  string              `bson:"product_name" json:"product_name"`
+	TopicName    string              `bson:"topic_name" json:"topic_name"`
+	CategoryName string              `bson:"category_name" json:"category_name"`
+	// PriceStore and PriceService are the effective unit prices, after any promotion.
+	PriceStore           float64 `bson:"price_store" json:"price_store"`
+	PriceService         float64 `bson:"price_service" json:"price_service"`
+	OriginalPriceStore   float64 `bson:"original_price_store" json:"original_price_store"`
+	OriginalPriceService float64 `bson:"original_price_service" json:"original_price_service"`
+	PromotionApplied     bool    `bson:"promotion_applied" json:"promotion_applied"`
+	PromotionName        string  `bson:"promotion_name,omitempty" json:"promotion_name,omitempty"`
+	// SavingsStore and SavingsService are the line savings for the current quantity.
+	SavingsStore   float64 `bson:"savings_store" json:"savings_store"`
+	SavingsService float64 `bson:"savings_service" json:"savings_service"`
+	Quantity       int     `bson:"quantity" json:"quantity"`
+	ImageURL       string  `bson:"image_url" json:"image_url"`
+	// Available, StockAvailable and StockCheckedAt record the last stock check. They may be stale
+	// until the line is added to, increased or checked out again; Available is nil if never checked.
+	Available      *bool      `bson:"available,omitempty" json:"available,omitempty"`
+	StockAvailable *int       `bson:"stock_available,omitempty" json:"stock_available,omitempty"`
+	StockCheckedAt *time.Time `bson:"stock_checked_at,omitempty" json:"stock_checked_at,omitempty"`
+	// UsageConfig is the product's usage config at the time the line was last priced.
+	UsageConfig *UsageConfig `bson:"usage_config,omitempty" json:"usage_config,omitempty"`
+}
+
+// SameLine - Reports whether the item is the cart line for this product and variation.
+func (i CartItem) SameLine(productID primitive.ObjectID, variation *VariationSelection) bool {
+	return i.ProductID == productID && i.Variation.Equal(variation)
 }
 
 type Cart struct {
-	ID                primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	TeacherID         string             `bson:"teacher_id" json:"teacher_id"`
-	StudentID         string             `bson:"student_id" json:"student_id"`
-	Items             []CartItem         `bson:"items" json:"items"`
-	TotalPriceStore   float64            `bson:"total_price_store" json:"total_price_store"`
-	TotalPriceService float64            `bson:"total_price_service" json:"total_price_service"`
-	CreateAt          time.Time          `bson:"create_at" json:"create_at"`
-	UpdateAt          time.Time          `bson:"update_at" json:"update_at"`
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	TeacherID string             `bson:"teacher_id" json:"teacher_id"`
+	StudentID string             `bson:"student_id" json:"student_id"`
+	// Named carts are prepared and checked out on their own. Each teacher and student pair
+	// also has one unnamed default cart, which the item endpoints use when no cart ID is given.
+	Name  string     `bson:"name,omitempty" json:"name,omitempty"`
+	Named bool       `bson:"named,omitempty" json:"named"`
+	Items []CartItem `bson:"items" json:"items"`
+	// SavedItems are parked for later. They are left out of the totals and of checkout.
+	SavedItems          []CartItem `bson:"saved_items" json:"saved_items"`
+	TotalPriceStore     float64    `bson:"total_price_store" json:"total_price_store"`
+	TotalPriceService   float64    `bson:"total_price_service" json:"total_price_service"`
+	TotalSavingsStore   float64    `bson:"total_savings_store" json:"total_savings_store"`
+	TotalSavingsService float64    `bson:"total_savings_service" json:"total_savings_service"`
+	// TotalPlannedUses and the usage times sum each line's usage config over its quantity.
+	TotalPlannedUses      int           `bson:"total_planned_uses" json:"total_planned_uses"`
+	TotalMinimumUsageTime int           `bson:"total_minimum_usage_time" json:"total_minimum_usage_time"`
+	TotalMaximumUsageTime int           `bson:"total_maximum_usage_time" json:"total_maximum_usage_time"`
+	Warnings              []CartWarning `bson:"warnings,omitempty" json:"warnings,omitempty"`
+	// Status is the cart's place in the approval workflow, and Approval its latest submission.
+	Status   string        `bson:"status,omitempty" json:"status,omitempty"`
+	Approval *CartApproval `bson:"approval,omitempty" json:"approval,omitempty"`
+	CreateAt time.Time     `bson:"create_at" json:"create_at"`
+	UpdateAt time.Time     `bson:"update_at" json:"update_at"`
+	// Budgets are the budgets that apply to the cart, worked out when it is returned; they are not stored.
+	Budgets []BudgetStatus `bson:"-" json:"budgets,omitempty"`
+}
+
+// OrderItem - A cart line as sent to order-service when the lines to order are listed explicitly.
+type OrderItem struct {
+	StudentID    string              `json:"student_id"`
+	ProductID    string              `json:"product_id"`
+	Variation    *VariationSelection `json:"variation,omitempty"`
+	Quantity     int                 `json:"quantity"`
+	PriceStore   float64             `json:"price_store"`
+	PriceService float64             `json:"price_service"`
+}
+
+// OrderItems - The cart's lines to order. Saved items are left out.
+func (c *Cart) OrderItems() []OrderItem {
+	items := make([]OrderItem, 0, len(c.Items))
+	for _, item := range c.Items {
+		items = append(items, OrderItem{
+			StudentID:    c.StudentID,
+			ProductID:    item.ProductID.Hex(),
+			Variation:    item.Variation,
+			Quantity:     item.Quantity,
+			PriceStore:   item.PriceStore,
+			PriceService: item.PriceService,
+		})
+	}
+	return items
+}
+
+// Refresh - Takes the current prices, promotion and availability from a freshly built line for the same product.
+func (i *CartItem) Refresh(latest CartItem) {
+	i.RefreshPrices(latest)
+	i.Available = latest.Available
+	i.StockAvailable = latest.StockAvailable
+	i.StockCheckedAt = latest.StockCheckedAt
+	i.UsageConfig = latest.UsageConfig
+}
+
+// RefreshPrices - Takes the current prices and promotion from a freshly built line for the same
+// product, and reports whether any of them changed.
+func (i *CartItem) RefreshPrices(latest CartItem) bool {
+	changed := i.PriceStore != latest.PriceStore ||
+		i.PriceService != latest.PriceService ||
+		i.OriginalPriceStore != latest.OriginalPriceStore ||
+		i.OriginalPriceService != latest.OriginalPriceService ||
+		i.PromotionApplied != latest.PromotionApplied ||
+		i.PromotionName != latest.PromotionName
+
+	i.PriceStore = latest.PriceStore
+	i.PriceService = latest.PriceService
+	i.OriginalPriceStore = latest.OriginalPriceStore
+	i.OriginalPriceService = latest.OriginalPriceService
+	i.PromotionApplied = latest.PromotionApplied
+	i.PromotionName = latest.PromotionName
+
+	return changed
+}
+
+// MarkAvailability - Records the outcome of a stock check. available is nil for untracked stock.
+func (i *CartItem) MarkAvailability(inStock bool, available *int, checkedAt time.Time) {
+	i.Available = &inStock
+	i.StockAvailable = available
+	i.StockCheckedAt = &checkedAt
+}
+
+// MergeCartLine - Adds the line to items, or adds its quantity to the existing line for the same product and variation.
+func MergeCartLine(items []CartItem, line CartItem) []CartItem {
+	for i := range items {
+		if items[i].SameLine(line.ProductID, line.Variation) {
+			items[i].Quantity += line.Quantity
+			items[i].Refresh(line)
+			return items
+		}
+	}
+	return append(items, line)
+}
+
+// CalculateTotals - Recomputes line savings, the cart totals from the effective prices and the
+// planned usage. Lines saved before original prices were recorded count as having no savings.
+func (c *Cart) CalculateTotals() {
+	totalPriceStore := 0.0
+	totalPriceService := 0.0
+	totalSavingsStore := 0.0
+	totalSavingsService := 0.0
+	c.TotalPlannedUses = 0
+	c.TotalMinimumUsageTime = 0
+	c.TotalMaximumUsageTime = 0
+
+	for i := range c.Items {
+		item := &c.Items[i]
+		quantity := float64(item.Quantity)
+
+		item.SavingsStore = 0
+		if item.OriginalPriceStore > item.PriceStore {
+			item.SavingsStore = RoundPrice((item.OriginalPriceStore - item.PriceStore) * quantity)
+		}
+		item.SavingsService = 0
+		if item.OriginalPriceService > item.PriceService {
+			item.SavingsService = RoundPrice((item.OriginalPriceService - item.PriceService) * quantity)
+		}
+
+		totalPriceStore += item.PriceStore * quantity
+		totalPriceService += item.PriceService * quantity
+		totalSavingsStore += item.SavingsStore
+		totalSavingsService += item.SavingsService
+
+		if usage := item.UsageConfig; usage != nil {
+			uses := usage.NumberOfUses * item.Quantity
+			c.TotalPlannedUses += uses
+			c.TotalMinimumUsageTime += usage.MinimumUsageTime * uses
+			c.TotalMaximumUsageTime += usage.MaximumUsageTime * uses
+		}
+	}
+
+	c.TotalPriceStore = RoundPrice(totalPriceStore)
+	c.TotalPriceService = RoundPrice(totalPriceService)
+	c.TotalSavingsStore = RoundPrice(totalSavingsStore)
+	c.TotalSavingsService = RoundPrice(totalSavingsService)
+}
+
+// SetWarning - Adds or replaces the warning with the given code.
+func (c *Cart) SetWarning(code string, message string) {
+	c.ClearWarning(code)
+	c.Warnings = append(c.Warnings, CartWarning{Code: code, Message: message})
+}
+
+// ClearWarning - Removes the warning with the given code, if present.
+func (c *Cart) ClearWarning(code string) {
+	warnings := c.Warnings[:0]
+	for _, warning := range c.Warnings {
+		if warning.Code != code {
+			warnings = append(warnings, warning)
+		}
+	}
+	c.Warnings = warnings
+}
+
+// RoundPrice - Rounds an amount to whole cents.
+func RoundPrice(value float64) float64 {
+	return math.Round(value*100) / 100
 }
@@ -0,0 +1,237 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRoundPrice(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  float64
+	}{
+		{value: 0, want: 0},
+		{value: 1.004, want: 1},
+		{value: 1.005000001, want: 1.01},
+		{value: 19.999, want: 20},
+		{value: 0.1 + 0.2, want: 0.3},
+		{value: -2.345, want: -2.35},
+	}
+
+	for _, tt := range tests {
+		if got := RoundPrice(tt.value); got != tt.want {
+			t.Errorf("RoundPrice(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCartItemSameLine(t *testing.T) {
+	shirt := primitive.NewObjectID()
+	item := CartItem{ProductID: shirt, Variation: &VariationSelection{VariationName: "Size", Option: "S"}}
+
+	tests := []struct {
+		name      string
+		productID primitive.ObjectID
+		variation *VariationSelection
+		want      bool
+	}{
+		{name: "same variation", productID: shirt, variation: &VariationSelection{VariationName: "Size", Option: "S"}, want: true},
+		{name: "other option", productID: shirt, variation: &VariationSelection{VariationName: "Size", Option: "M"}},
+		{name: "other variation name", productID: shirt, variation: &VariationSelection{VariationName: "Color", Option: "S"}},
+		{name: "no variation", productID: shirt},
+		{name: "other product", productID: primitive.NewObjectID(), variation: &VariationSelection{VariationName: "Size", Option: "S"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := item.SameLine(tt.productID, tt.variation); got != tt.want {
+				t.Errorf("SameLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if plain := (CartItem{ProductID: shirt}); !plain.SameLine(shirt, nil) {
+		t.Error("a line without a variation should match a selection without one")
+	}
+}
+
+func TestMergeCartLine(t *testing.T) {
+	pen := primitive.NewObjectID()
+	shirt := primitive.NewObjectID()
+	small := &VariationSelection{VariationName: "Size", Option: "S"}
+	medium := &VariationSelection{VariationName: "Size", Option: "M"}
+
+	tests := []struct {
+		name  string
+		items []CartItem
+		line  CartItem
+		want  []CartItem
+	}{
+		{
+			name:  "new product",
+			items: []CartItem{{ProductID: pen, Quantity: 1, PriceStore: 2}},
+			line:  CartItem{ProductID: shirt, Quantity: 2, PriceStore: 10},
+			want:  []CartItem{{ProductID: pen, Quantity: 1, PriceStore: 2}, {ProductID: shirt, Quantity: 2, PriceStore: 10}},
+		},
+		{
+			name:  "same product adds up and takes the latest price",
+			items: []CartItem{{ProductID: pen, Quantity: 1, PriceStore: 2, OriginalPriceStore: 2}},
+			line:  CartItem{ProductID: pen, Quantity: 3, PriceStore: 1.5, OriginalPriceStore: 2, PromotionApplied: true, PromotionName: "Back to school"},
+			want:  []CartItem{{ProductID: pen, Quantity: 4, PriceStore: 1.5, OriginalPriceStore: 2, PromotionApplied: true, PromotionName: "Back to school"}},
+		},
+		{
+			name:  "same variation adds up",
+			items: []CartItem{{ProductID: shirt, Variation: small, Quantity: 1, PriceStore: 10}},
+			line:  CartItem{ProductID: shirt, Variation: &VariationSelection{VariationName: "Size", Option: "S"}, Quantity: 1, PriceStore: 10},
+			want:  []CartItem{{ProductID: shirt, Variation: small, Quantity: 2, PriceStore: 10}},
+		},
+		{
+			name:  "other variation is a separate line",
+			items: []CartItem{{ProductID: shirt, Variation: small, Quantity: 1, PriceStore: 10}},
+			line:  CartItem{ProductID: shirt, Variation: medium, Quantity: 1, PriceStore: 12},
+			want:  []CartItem{{ProductID: shirt, Variation: small, Quantity: 1, PriceStore: 10}, {ProductID: shirt, Variation: medium, Quantity: 1, PriceStore: 12}},
+		},
+		{
+			name:  "variation and no variation are separate lines",
+			items: []CartItem{{ProductID: shirt, Quantity: 1, PriceStore: 10}},
+			line:  CartItem{ProductID: shirt, Variation: small, Quantity: 1, PriceStore: 10},
+			want:  []CartItem{{ProductID: shirt, Quantity: 1, PriceStore: 10}, {ProductID: shirt, Variation: small, Quantity: 1, PriceStore: 10}},
+		},
+		{
+			name: "empty cart",
+			line: CartItem{ProductID: pen, Quantity: 1, PriceStore: 2},
+			want: []CartItem{{ProductID: pen, Quantity: 1, PriceStore: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeCartLine(tt.items, tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeCartLine() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateTotals(t *testing.T) {
+	tests := []struct {
+		name string
+		cart Cart
+		want Cart
+	}{
+		{
+			name: "empty cart",
+			cart: Cart{},
+			want: Cart{},
+		},
+		{
+			name: "effective prices and savings",
+			cart: Cart{Items: []CartItem{
+				{Quantity: 3, PriceStore: 0.1, PriceService: 0.2, OriginalPriceStore: 0.15, OriginalPriceService: 0.2},
+				{Quantity: 2, PriceStore: 9.99, PriceService: 4.5, OriginalPriceStore: 9.99, OriginalPriceService: 5},
+			}},
+			want: Cart{
+				TotalPriceStore:     20.28,
+				TotalPriceService:   9.6,
+				TotalSavingsStore:   0.15,
+				TotalSavingsService: 1,
+			},
+		},
+		{
+			name: "lines without original prices have no savings",
+			cart: Cart{Items: []CartItem{{Quantity: 2, PriceStore: 5, PriceService: 1}}},
+			want: Cart{TotalPriceStore: 10, TotalPriceService: 2},
+		},
+		{
+			name: "planned usage",
+			cart: Cart{Items: []CartItem{
+				{Quantity: 2, UsageConfig: &UsageConfig{NumberOfUses: 3, MinimumUsageTime: 10, MaximumUsageTime: 20}},
+				{Quantity: 1, UsageConfig: &UsageConfig{NumberOfUses: 1, MinimumUsageTime: 5, MaximumUsageTime: 5}},
+				{Quantity: 4},
+			}},
+			want: Cart{TotalPlannedUses: 7, TotalMinimumUsageTime: 65, TotalMaximumUsageTime: 125},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := tt.cart
+			cart.CalculateTotals()
+
+			got := [7]float64{cart.TotalPriceStore, cart.TotalPriceService, cart.TotalSavingsStore, cart.TotalSavingsService,
+				float64(cart.TotalPlannedUses), float64(cart.TotalMinimumUsageTime), float64(cart.TotalMaximumUsageTime)}
+			want := [7]float64{tt.want.TotalPriceStore, tt.want.TotalPriceService, tt.want.TotalSavingsStore, tt.want.TotalSavingsService,
+				float64(tt.want.TotalPlannedUses), float64(tt.want.TotalMinimumUsageTime), float64(tt.want.TotalMaximumUsageTime)}
+
+			if got != want {
+				t.Errorf("totals = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCalculateTotalsLineSavings(t *testing.T) {
+	cart := Cart{Items: []CartItem{{Quantity: 3, PriceStore: 7.5, OriginalPriceStore: 10, PriceService: 3, OriginalPriceService: 2}}}
+	cart.CalculateTotals()
+
+	item := cart.Items[0]
+	if item.SavingsStore != 7.5 {
+		t.Errorf("SavingsStore = %v, want 7.5", item.SavingsStore)
+	}
+	// A price above the original is not a saving.
+	if item.SavingsService != 0 {
+		t.Errorf("SavingsService = %v, want 0", item.SavingsService)
+	}
+}
+
+func TestRefreshPrices(t *testing.T) {
+	promoted := CartItem{PriceStore: 8, PriceService: 4, OriginalPriceStore: 10, OriginalPriceService: 4, PromotionApplied: true, PromotionName: "Spring"}
+	regular := CartItem{PriceStore: 10, PriceService: 4, OriginalPriceStore: 10, OriginalPriceService: 4}
+
+	tests := []struct {
+		name        string
+		item        CartItem
+		latest      CartItem
+		wantChanged bool
+	}{
+		{name: "unchanged", item: regular, latest: regular},
+		{name: "promotion ended", item: promoted, latest: regular, wantChanged: true},
+		{name: "promotion started", item: regular, latest: promoted, wantChanged: true},
+		{name: "promotion renamed", item: promoted, latest: CartItem{PriceStore: 8, PriceService: 4, OriginalPriceStore: 10, OriginalPriceService: 4, PromotionApplied: true, PromotionName: "Summer"}, wantChanged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := tt.item
+			item.Quantity = 2
+
+			if changed := item.RefreshPrices(tt.latest); changed != tt.wantChanged {
+				t.Errorf("RefreshPrices() = %v, want %v", changed, tt.wantChanged)
+			}
+
+			want := tt.latest
+			want.Quantity = 2
+			if !reflect.DeepEqual(item, want) {
+				t.Errorf("item = %+v, want %+v", item, want)
+			}
+		})
+	}
+}
+
+func TestOrderItems(t *testing.T) {
+	productID := primitive.NewObjectID()
+	variation := &VariationSelection{VariationName: "Size", Option: "M"}
+
+	cart := Cart{
+		StudentID:  "student-1",
+		Items:      []CartItem{{ProductID: productID, Variation: variation, Quantity: 2, PriceStore: 8, PriceService: 1}},
+		SavedItems: []CartItem{{ProductID: primitive.NewObjectID(), Quantity: 1}},
+	}
+
+	want := []OrderItem{{StudentID: "student-1", ProductID: productID.Hex(), Variation: variation, Quantity: 2, PriceStore: 8, PriceService: 1}}
+	if got := cart.OrderItems(); !reflect.DeepEqual(got, want) {
+		t.Errorf("OrderItems() = %+v, want %+v", got, want)
+	}
+}
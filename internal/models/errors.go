@@ -0,0 +1,74 @@
+package models
+
+// ServiceError - An error raised by the service layer together with the HTTP status,
+// error code and optional details it should be reported with.
+type ServiceError struct {
+	StatusCode int
+	ErrorCode  string
+	Message    string
+	Details    interface{}
+}
+
+func NewServiceError(statusCode int, errorCode string, message string, details interface{}) *ServiceError {
+	return &ServiceError{
+		StatusCode: statusCode,
+		ErrorCode:  errorCode,
+		Message:    message,
+		Details:    details,
+	}
+}
+
+func (e *ServiceError) Error() string {
+	return e.Message
+}
+
+// FieldError - One failed validation rule on a request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// PurchaseLimitDetail - How much of a product's purchase limit is used and what is still allowed.
+// StudentID is empty when the limit is counted across all of a teacher's carts.
+type PurchaseLimitDetail struct {
+	ProductID     string `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	StudentID     string `json:"student_id,omitempty"`
+	PurchaseLimit int    `json:"purchase_limit"`
+	Ordered       int    `json:"ordered"`
+	InCart        int    `json:"in_cart"`
+	Requested     int    `json:"requested"`
+	Remaining     int    `json:"remaining"`
+}
+
+// StockDetail - A cart line that cannot be fulfilled from the current stock.
+// Reason is "out_of_stock", "insufficient_stock" or "insufficient_promotion_stock".
+type StockDetail struct {
+	ProductID   string              `json:"product_id"`
+	ProductName string              `json:"product_name"`
+	StudentID   string              `json:"student_id,omitempty"`
+	Variation   *VariationSelection `json:"variation,omitempty"`
+	Requested   int                 `json:"requested"`
+	Available   int                 `json:"available"`
+	Reason      string              `json:"reason"`
+}
+
+// UsageBudgetDetail - A student's planned minimum usage time per week against the weekly budget, in
+// minutes, with the cart's uses spread over PlanningWeeks weeks.
+type UsageBudgetDetail struct {
+	StudentID     string `json:"student_id"`
+	WeeklyBudget  int    `json:"weekly_budget"`
+	PlanningWeeks int    `json:"planning_weeks"`
+	Planned       int    `json:"planned"`
+	Projected     int    `json:"projected"`
+}
+
+// ApprovalDetail - A cart that needs approval before it can be ordered, with the rules it matches.
+type ApprovalDetail struct {
+	CartID    string   `json:"cart_id"`
+	StudentID string   `json:"student_id"`
+	Status    string   `json:"status"`
+	Reasons   []string `json:"reasons"`
+}
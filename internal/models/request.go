@@ -1,20 +1,35 @@
 package models
 
+import "time"
+
+// CartID, on item requests, selects a named cart; the student's default cart is used when it is empty.
 type AddToCartRequest struct {
-	ProductID string `json:"product_id" validate:"required"`
-	TeacherID string `json:"teacher_id" validate:"required"`
-	StudentID string `json:"student_id" validate:"required"`
-	Quantity  int    `json:"quantity" validate:"required,min=1"`
+	CartID    string              `json:"cart_id"`
+	ProductID string              `json:"product_id" validate:"required"`
+	TeacherID string              `json:"teacher_id" validate:"required"`
+	OwnerID   string              `json:"owner_id"`
+	StudentID string              `json:"student_id" validate:"required"`
+	Quantity  int                 `json:"quantity" validate:"required,min=1"`
+	Variation *VariationSelection `json:"variation"`
 }
 
 type UserRequest struct {
+	CartID    string              `json:"cart_id"`
+	TeacherID string              `json:"teacher_id" validate:"required"`
+	OwnerID   string              `json:"owner_id"`
+	StudentID string              `json:"student_id" validate:"required"`
+	Variation *VariationSelection `json:"variation"`
+}
+
+type TeacherHistoryRequest struct {
 	TeacherID string `json:"teacher_id" validate:"required"`
-	StudentID string `json:"student_id" validate:"required"`
 }
 
 type CheckOutCartRequest struct {
-	TeacherID string  `json:"teacher_id" validate:"required"`
-	StudentID string  `json:"student_id" validate:"required"`
+	TeacherID string `json:"teacher_id" validate:"required"`
+	OwnerID   string `json:"owner_id"`
+	// StudentID is accepted for older clients; checkout covers all of the teacher's carts.
+	StudentID string  `json:"student_id"`
 	Email     string  `json:"email" validate:"required,email"`
 	Types     string  `json:"types" validate:"required,oneof=cod bank_transfer"`
 	Street    string  `json:"street" validate:"required"`
@@ -25,8 +40,135 @@ type CheckOutCartRequest struct {
 }
 
 type UpdateCartItemRequest struct {
-	Quantity  *int   `json:"quantity" validate:"required,min=1"`
-	Type      string `json:"types" validate:"required,oneof=increase decrease"`
+	CartID    string              `json:"cart_id"`
+	Quantity  *int                `json:"quantity" validate:"required,min=1"`
+	Type      string              `json:"types" validate:"required,oneof=increase decrease"`
+	TeacherID string              `json:"teacher_id" validate:"required"`
+	OwnerID   string              `json:"owner_id"`
+	StudentID string              `json:"student_id" validate:"required"`
+	Variation *VariationSelection `json:"variation"`
+}
+
+type CreateWishlistRequest struct {
 	TeacherID string `json:"teacher_id" validate:"required"`
+	StudentID string `json:"student_id"`
+	Name      string `json:"name" validate:"required,max=100"`
+}
+
+type AddWishlistItemRequest struct {
+	TeacherID string              `json:"teacher_id" validate:"required"`
+	ProductID string              `json:"product_id" validate:"required"`
+	Variation *VariationSelection `json:"variation"`
+}
+
+type WishlistItemRequest struct {
+	TeacherID string              `json:"teacher_id" validate:"required"`
+	Variation *VariationSelection `json:"variation"`
+}
+
+// MoveWishlistItemRequest - StudentID is required when the wishlist is not kept for a student.
+type MoveWishlistItemRequest struct {
+	TeacherID string              `json:"teacher_id" validate:"required"`
+	StudentID string              `json:"student_id"`
+	Quantity  int                 `json:"quantity" validate:"required,min=1"`
+	Variation *VariationSelection `json:"variation"`
+}
+
+type CreateCartRequest struct {
+	TeacherID string `json:"teacher_id" validate:"required"`
+	OwnerID   string `json:"owner_id"`
 	StudentID string `json:"student_id" validate:"required"`
+	Name      string `json:"name" validate:"required,max=100"`
+}
+
+type RenameCartRequest struct {
+	TeacherID string `json:"teacher_id" validate:"required"`
+	OwnerID   string `json:"owner_id"`
+	Name      string `json:"name" validate:"required,max=100"`
+}
+
+// CopyCartRequest - Copies a student's cart into the default carts of other students. SourceCartID
+// picks a named cart of the source student; the default cart is copied when it is empty.
+type CopyCartRequest struct {
+	TeacherID        string   `json:"teacher_id" validate:"required"`
+	OwnerID          string   `json:"owner_id"`
+	SourceStudentID  string   `json:"source_student_id" validate:"required"`
+	SourceCartID     string   `json:"source_cart_id"`
+	TargetStudentIDs []string `json:"target_student_ids" validate:"required,min=1,dive,required"`
+	Mode             string   `json:"mode" validate:"required,oneof=merge replace"`
+}
+
+// BulkAddToCartRequest - Adds many lines at once. With Atomic set, nothing is added unless every
+// line can be; otherwise each line that passes is added and the others are reported.
+type BulkAddToCartRequest struct {
+	TeacherID string        `json:"teacher_id" validate:"required"`
+	OwnerID   string        `json:"owner_id"`
+	Atomic    bool          `json:"atomic"`
+	Items     []BulkAddLine `json:"items" validate:"required,min=1,max=200,dive"`
+}
+
+type BulkAddLine struct {
+	CartID    string              `json:"cart_id"`
+	StudentID string              `json:"student_id" validate:"required"`
+	ProductID string              `json:"product_id" validate:"required"`
+	Quantity  int                 `json:"quantity" validate:"required,min=1"`
+	Variation *VariationSelection `json:"variation"`
+}
+
+// GrantCartAccessRequest - Gives another teacher access to the caller's carts, for one student or,
+// with StudentID empty, for all of them. Granting again replaces the permission.
+type GrantCartAccessRequest struct {
+	TeacherID  string `json:"teacher_id" validate:"required"`
+	MemberID   string `json:"member_id" validate:"required"`
+	StudentID  string `json:"student_id"`
+	Permission string `json:"permission" validate:"required,oneof=view edit checkout"`
+}
+
+// TransferCartsRequest - Moves students' carts from one teacher to another. On the teacher endpoint
+// FromTeacherID defaults to the caller. Conflict decides the quantity of a line that both teachers'
+// default carts for a student hold: "sum", "keep_source" or "keep_target".
+type TransferCartsRequest struct {
+	TeacherID     string   `json:"teacher_id" validate:"required"`
+	FromTeacherID string   `json:"from_teacher_id"`
+	ToTeacherID   string   `json:"to_teacher_id" validate:"required"`
+	StudentIDs    []string `json:"student_ids" validate:"required,min=1,max=200,dive,required"`
+	Conflict      string   `json:"conflict" validate:"required,oneof=sum keep_source keep_target"`
+}
+
+// CreateShareLinkRequest - CartID picks a named cart of the student; the default cart is shared when
+// it is empty. ExpiresInHours falls back to the configured default and is capped at the maximum.
+type CreateShareLinkRequest struct {
+	TeacherID      string `json:"teacher_id" validate:"required"`
+	OwnerID        string `json:"owner_id"`
+	StudentID      string `json:"student_id" validate:"required"`
+	CartID         string `json:"cart_id"`
+	ExpiresInHours int    `json:"expires_in_hours" validate:"omitempty,min=1"`
+}
+
+// SubmitCartRequest - Submits the student's default cart, or the named cart with CartID, for approval.
+type SubmitCartRequest struct {
+	TeacherID string `json:"teacher_id" validate:"required"`
+	OwnerID   string `json:"owner_id"`
+	StudentID string `json:"student_id" validate:"required"`
+	CartID    string `json:"cart_id"`
+}
+
+// CartDecisionRequest - An approver's decision on a submitted cart. A rejection needs a comment.
+type CartDecisionRequest struct {
+	ApproverID string `json:"approver_id" validate:"required"`
+	Comment    string `json:"comment" validate:"max=500"`
+}
+
+// BudgetRequest - Creates or replaces a budget. At least one of the limits must be set; Mode
+// defaults to "reject". Only one budget per student or teacher may use a term name.
+type BudgetRequest struct {
+	AdminID      string    `json:"admin_id" validate:"required"`
+	Scope        string    `json:"scope" validate:"required,oneof=student teacher"`
+	ScopeID      string    `json:"scope_id" validate:"required"`
+	Term         string    `json:"term" validate:"required,max=100"`
+	StartsAt     time.Time `json:"starts_at" validate:"required"`
+	EndsAt       time.Time `json:"ends_at" validate:"required,gtfield=StartsAt"`
+	LimitStore   float64   `json:"limit_store" validate:"min=0"`
+	LimitService float64   `json:"limit_service" validate:"min=0"`
+	Mode         string    `json:"mode" validate:"omitempty,oneof=warn reject"`
 }
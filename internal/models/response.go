@@ -6,9 +6,36 @@ type APIResponse struct  {
 	Data interface{} `json:"data,omitempty"`
 	Error string `json:"error,omitempty"`
 	ErrorCode string `json:"error_code,omitempty"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 const (
     ErrInvalidOperation   = "ERR_INVALID_OPERATION"
     ErrInvalidRequest     = "ERR_INVALID_REQUEST"
+    ErrValidation         = "ERR_VALIDATION"
+    ErrUnauthorized       = "ERR_UNAUTHORIZED"
+    ErrForbidden          = "ERR_FORBIDDEN"
+    ErrStudentNotAssigned = "ERR_STUDENT_NOT_ASSIGNED"
+    ErrDependencyFailure  = "ERR_DEPENDENCY_FAILURE"
+    ErrRateLimited        = "ERR_RATE_LIMITED"
+    ErrProductNotFound    = "ERR_PRODUCT_NOT_FOUND"
+    ErrInvalidVariation   = "ERR_INVALID_VARIATION"
+    ErrPurchaseLimit      = "ERR_PURCHASE_LIMIT_EXCEEDED"
+    ErrInsufficientStock  = "ERR_INSUFFICIENT_STOCK"
+    ErrUsageBudget        = "ERR_USAGE_BUDGET_EXCEEDED"
+    ErrCartItemNotFound   = "ERR_CART_ITEM_NOT_FOUND"
+    ErrWishlistNotFound   = "ERR_WISHLIST_NOT_FOUND"
+    ErrCartNotFound       = "ERR_CART_NOT_FOUND"
+    ErrCartNameTaken      = "ERR_CART_NAME_TAKEN"
+    ErrCartModified       = "ERR_CART_MODIFIED"
+    ErrBulkAddFailed      = "ERR_BULK_ADD_FAILED"
+    ErrCartAccessDenied   = "ERR_CART_ACCESS_DENIED"
+    ErrCartMemberNotFound = "ERR_CART_MEMBER_NOT_FOUND"
+    ErrShareLinkNotFound  = "ERR_SHARE_LINK_NOT_FOUND"
+    ErrShareLinkInvalid   = "ERR_SHARE_LINK_INVALID"
+    ErrApprovalRequired   = "ERR_APPROVAL_REQUIRED"
+    ErrCartStatus         = "ERR_INVALID_CART_STATUS"
+    ErrBudgetExceeded     = "ERR_BUDGET_EXCEEDED"
+    ErrBudgetNotFound     = "ERR_BUDGET_NOT_FOUND"
+    ErrBudgetConflict     = "ERR_BUDGET_CONFLICT"
 )
\ No newline at end of file
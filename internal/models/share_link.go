@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShareLink - A read-only link to a student's cart, for parents. CartID is nil for the default cart.
+type ShareLink struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	TeacherID string              `bson:"teacher_id" json:"teacher_id"`
+	StudentID string              `bson:"student_id" json:"student_id"`
+	CartID    *primitive.ObjectID `bson:"cart_id,omitempty" json:"cart_id,omitempty"`
+	CreatedBy string              `bson:"created_by" json:"created_by"`
+	ExpiresAt time.Time           `bson:"expires_at" json:"expires_at"`
+	RevokedAt *time.Time          `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreateAt  time.Time           `bson:"create_at" json:"create_at"`
+}
+
+// Active - Reports whether the link has neither expired nor been revoked.
+func (l ShareLink) Active(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// ShareLinkToken - A new share link with its token. The token is only returned when the link is created.
+type ShareLinkToken struct {
+	ShareLink
+	Token string `json:"token"`
+}
+
+// SharedCart - What a share link shows: the cart without teacher IDs or product IDs.
+type SharedCart struct {
+	Name              string           `json:"name,omitempty"`
+	Items             []SharedCartItem `json:"items"`
+	TotalPriceStore   float64          `json:"total_price_store"`
+	TotalPriceService float64          `json:"total_price_service"`
+	UpdateAt          time.Time        `json:"update_at"`
+	ExpiresAt         time.Time        `json:"expires_at"`
+}
+
+type SharedCartItem struct {
+	ProductName  string              `json:"product_name"`
+	TopicName    string              `json:"topic_name,omitempty"`
+	CategoryName string              `json:"category_name,omitempty"`
+	ImageURL     string              `json:"image_url,omitempty"`
+	Variation    *VariationSelection `json:"variation,omitempty"`
+	Quantity     int                 `json:"quantity"`
+	PriceStore   float64             `json:"price_store"`
+	PriceService float64             `json:"price_service"`
+}
+
+// NewSharedCart - Builds the public view of the cart behind a share link.
+func NewSharedCart(cart *Cart, link *ShareLink) *SharedCart {
+	shared := &SharedCart{
+		Name:              cart.Name,
+		Items:             make([]SharedCartItem, 0, len(cart.Items)),
+		TotalPriceStore:   cart.TotalPriceStore,
+		TotalPriceService: cart.TotalPriceService,
+		UpdateAt:          cart.UpdateAt,
+		ExpiresAt:         link.ExpiresAt,
+	}
+
+	for _, item := range cart.Items {
+		shared.Items = append(shared.Items, SharedCartItem{
+			ProductName:  item.ProductName,
+			TopicName:    item.TopicName,
+			CategoryName: item.CategoryName,
+			ImageURL:     item.ImageURL,
+			Variation:    item.Variation,
+			Quantity:     item.Quantity,
+			PriceStore:   item.PriceStore,
+			PriceService: item.PriceService,
+		})
+	}
+
+	return shared
+}
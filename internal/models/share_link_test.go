@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestShareLinkActive(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Minute)
+
+	tests := []struct {
+		name string
+		link ShareLink
+		want bool
+	}{
+		{name: "active", link: ShareLink{ExpiresAt: now.Add(time.Hour)}, want: true},
+		{name: "expired", link: ShareLink{ExpiresAt: now.Add(-time.Second)}},
+		{name: "expires now", link: ShareLink{ExpiresAt: now}},
+		{name: "revoked", link: ShareLink{ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.link.Active(now); got != tt.want {
+				t.Errorf("Active() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSharedCart(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	cart := &Cart{
+		TeacherID:         "teacher-1",
+		StudentID:         "student-1",
+		Name:              "Art class",
+		Items:             []CartItem{{ProductID: primitive.NewObjectID(), ProductName: "Crayons", Quantity: 2, PriceStore: 3, PriceService: 1}},
+		SavedItems:        []CartItem{{ProductID: primitive.NewObjectID(), ProductName: "Easel", Quantity: 1}},
+		TotalPriceStore:   6,
+		TotalPriceService: 2,
+	}
+
+	shared := NewSharedCart(cart, &ShareLink{ExpiresAt: expiresAt})
+
+	if shared.Name != "Art class" || shared.TotalPriceStore != 6 || shared.TotalPriceService != 2 || !shared.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("NewSharedCart() = %+v", shared)
+	}
+	if len(shared.Items) != 1 || shared.Items[0] != (SharedCartItem{ProductName: "Crayons", Quantity: 2, PriceStore: 3, PriceService: 1}) {
+		t.Errorf("items = %+v, want only the active line without product IDs", shared.Items)
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WishlistEntry - A product kept on a wishlist, with the product details seen when it was added.
+type WishlistEntry struct {
+	ProductID    primitive.ObjectID  `bson:"product_id" json:"product_id"`
+	Variation    *VariationSelection `bson:"variation,omitempty" json:"variation,omitempty"`
+	ProductName  string              `bson:"product_name" json:"product_name"`
+	TopicName    string              `bson:"topic_name" json:"topic_name"`
+	CategoryName string              `bson:"category_name" json:"category_name"`
+	PriceStore   float64             `bson:"price_store" json:"price_store"`
+	PriceService float64             `bson:"price_service" json:"price_service"`
+	ImageURL     string              `bson:"image_url" json:"image_url"`
+	AddedAt      time.Time           `bson:"added_at" json:"added_at"`
+}
+
+// SameLine - Reports whether the entry is for this product and variation.
+func (e WishlistEntry) SameLine(productID primitive.ObjectID, variation *VariationSelection) bool {
+	return e.ProductID == productID && e.Variation.Equal(variation)
+}
+
+// Wishlist - A named list of products a teacher is considering, optionally kept for one student.
+type Wishlist struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	TeacherID string             `bson:"teacher_id" json:"teacher_id"`
+	StudentID string             `bson:"student_id,omitempty" json:"student_id,omitempty"`
+	Name      string             `bson:"name" json:"name"`
+	Items     []WishlistEntry    `bson:"items" json:"items"`
+	CreateAt  time.Time          `bson:"create_at" json:"create_at"`
+	UpdateAt  time.Time          `bson:"update_at" json:"update_at"`
+}
+
+// WishlistEntryStatus - A wishlist entry compared with the product as it is now.
+// Reason is "product_not_found", "invalid_variation" or "out_of_stock" when the entry is unavailable.
+type WishlistEntryStatus struct {
+	WishlistEntry
+	Available           bool    `json:"available"`
+	Reason              string  `json:"reason,omitempty"`
+	PriceChanged        bool    `json:"price_changed"`
+	CurrentPriceStore   float64 `json:"current_price_store"`
+	CurrentPriceService float64 `json:"current_price_service"`
+}
+
+// WishlistReport - A wishlist with the current availability and price of each entry.
+type WishlistReport struct {
+	Wishlist
+	Items []WishlistEntryStatus `json:"items"`
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"store/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type BudgetRepository interface {
+	CreateBudget(ctx context.Context, budget *models.Budget) error
+	GetBudget(ctx context.Context, budgetID primitive.ObjectID) (*models.Budget, error)
+	GetBudgets(ctx context.Context, scope string, scopeID string) ([]models.Budget, error)
+	GetActiveBudgets(ctx context.Context, scope string, scopeIDs []string, at time.Time) ([]models.Budget, error)
+	UpdateBudget(ctx context.Context, budget *models.Budget) error
+	DeleteBudget(ctx context.Context, budgetID primitive.ObjectID) (bool, error)
+}
+
+type budgetRepository struct {
+	collection *mongo.Collection
+}
+
+func NewBudgetRepository(collection *mongo.Collection) BudgetRepository {
+	return &budgetRepository{
+		collection: collection,
+	}
+}
+
+func (r *budgetRepository) CreateBudget(ctx context.Context, budget *models.Budget) error {
+
+	budget.ID = primitive.NewObjectID()
+	budget.CreateAt = time.Now()
+	budget.UpdateAt = budget.CreateAt
+
+	_, err := r.collection.InsertOne(ctx, budget)
+
+	return err
+}
+
+// GetBudget - Returns the budget, or nil when it does not exist.
+func (r *budgetRepository) GetBudget(ctx context.Context, budgetID primitive.ObjectID) (*models.Budget, error) {
+
+	var budget models.Budget
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": budgetID}).Decode(&budget)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &budget, nil
+}
+
+// GetBudgets - Lists budgets, narrowed to a scope and to one student or teacher when they are set.
+func (r *budgetRepository) GetBudgets(ctx context.Context, scope string, scopeID string) ([]models.Budget, error) {
+
+	filter := bson.M{}
+	if scope != "" {
+		filter["scope"] = scope
+	}
+	if scopeID != "" {
+		filter["scope_id"] = scopeID
+	}
+
+	return r.find(ctx, filter)
+}
+
+// GetActiveBudgets - Lists the budgets of the students or teachers whose term includes at.
+func (r *budgetRepository) GetActiveBudgets(ctx context.Context, scope string, scopeIDs []string, at time.Time) ([]models.Budget, error) {
+
+	if len(scopeIDs) == 0 {
+		return []models.Budget{}, nil
+	}
+
+	return r.find(ctx, bson.M{
+		"scope":     scope,
+		"scope_id":  bson.M{"$in": scopeIDs},
+		"starts_at": bson.M{"$lte": at},
+		"ends_at":   bson.M{"$gt": at},
+	})
+}
+
+func (r *budgetRepository) UpdateBudget(ctx context.Context, budget *models.Budget) error {
+
+	budget.UpdateAt = time.Now()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": budget.ID}, bson.M{
+		"$set": bson.M{
+			"scope":         budget.Scope,
+			"scope_id":      budget.ScopeID,
+			"term":          budget.Term,
+			"starts_at":     budget.StartsAt,
+			"ends_at":       budget.EndsAt,
+			"limit_store":   budget.LimitStore,
+			"limit_service": budget.LimitService,
+			"mode":          budget.Mode,
+			"update_at":     budget.UpdateAt,
+		},
+	})
+
+	return err
+}
+
+func (r *budgetRepository) DeleteBudget(ctx context.Context, budgetID primitive.ObjectID) (bool, error) {
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": budgetID})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount > 0, nil
+}
+
+func (r *budgetRepository) find(ctx context.Context, filter bson.M) ([]models.Budget, error) {
+
+	opts := options.Find().SetSort(bson.D{{Key: "starts_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	budgets := []models.Budget{}
+	if err := cursor.All(ctx, &budgets); err != nil {
+		return nil, err
+	}
+
+	return budgets, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"store/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CartMemberRepository interface {
+	UpsertMember(ctx context.Context, member *models.CartMember) error
+	DeleteMember(ctx context.Context, ownerID string, memberID string, studentID string) (bool, error)
+	GetMembersByOwner(ctx context.Context, ownerID string) ([]models.CartMember, error)
+	GetMembershipsByMember(ctx context.Context, memberID string) ([]models.CartMember, error)
+	GetMemberships(ctx context.Context, ownerID string, memberID string) ([]models.CartMember, error)
+}
+
+type cartMemberRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCartMemberRepository(collection *mongo.Collection) CartMemberRepository {
+	return &cartMemberRepository{
+		collection: collection,
+	}
+}
+
+// UpsertMember - Saves the grant, replacing the permission of an existing grant for the same owner,
+// member and student.
+func (r *cartMemberRepository) UpsertMember(ctx context.Context, member *models.CartMember) error {
+
+	now := time.Now()
+	filter := bson.M{"owner_id": member.OwnerID, "member_id": member.MemberID, "student_id": member.StudentID}
+
+	update := bson.M{
+		"$set":         bson.M{"permission": member.Permission, "update_at": now},
+		"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "create_at": now},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	return r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(member)
+}
+
+func (r *cartMemberRepository) DeleteMember(ctx context.Context, ownerID string, memberID string, studentID string) (bool, error) {
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"owner_id": ownerID, "member_id": memberID, "student_id": studentID})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount > 0, nil
+}
+
+func (r *cartMemberRepository) GetMembersByOwner(ctx context.Context, ownerID string) ([]models.CartMember, error) {
+	return r.find(ctx, bson.M{"owner_id": ownerID})
+}
+
+func (r *cartMemberRepository) GetMembershipsByMember(ctx context.Context, memberID string) ([]models.CartMember, error) {
+	return r.find(ctx, bson.M{"member_id": memberID})
+}
+
+// GetMemberships - Returns every grant the owner has given the member.
+func (r *cartMemberRepository) GetMemberships(ctx context.Context, ownerID string, memberID string) ([]models.CartMember, error) {
+	return r.find(ctx, bson.M{"owner_id": ownerID, "member_id": memberID})
+}
+
+func (r *cartMemberRepository) find(ctx context.Context, filter bson.M) ([]models.CartMember, error) {
+
+	opts := options.Find().SetSort(bson.D{{Key: "create_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	members := []models.CartMember{}
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
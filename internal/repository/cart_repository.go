@@ -3,27 +3,46 @@ package repository
 import (
 	"context"
 	"fmt"
-	"math"
+	"net/http"
 	"store/internal/models"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type CartRepository interface {
 	GetCartByTeacherStudent(ctx context.Context, teacherID string, studentID string) (*models.Cart, error)
+	GetCartByID(ctx context.Context, teacherID string, cartID primitive.ObjectID) (*models.Cart, error)
+	FindCart(ctx context.Context, cartID primitive.ObjectID) (*models.Cart, error)
+	GetCartsByStatus(ctx context.Context, status string) ([]models.Cart, error)
 	GetAllCartGroupedByTeacher(ctx context.Context) ([]bson.M, error)
 	GetCartByTeacher(ctx context.Context, teacherID string) ([]bson.M, error)
+	GetCartsByTeacher(ctx context.Context, teacherID string) ([]models.Cart, error)
+	GetNamedCarts(ctx context.Context, teacherID string, studentID string) ([]models.Cart, error)
+	GetCartsByStudent(ctx context.Context, teacherID string, studentID string) ([]models.Cart, error)
+	CreateCart(ctx context.Context, cart *models.Cart) error
+	RenameCart(ctx context.Context, cart *models.Cart, name string) error
+	DeleteCart(ctx context.Context, cart *models.Cart) error
+	ReassignCart(ctx context.Context, cart *models.Cart, teacherID string, name string) error
 	UpdateCart(ctx context.Context, cart *models.Cart) error
-	AddItemToCart(ctx context.Context, teacherID string, studentID string, item models.CartItem) error
-	UpdateCartItemQuantity(ctx context.Context, teacherID string, studentID string, productID primitive.ObjectID, quantity int, types string, item models.CartItem) error
-	RemoveFromCart(ctx context.Context, teacherID string, studentID string, productID primitive.ObjectID) error
+	UpdateCartStatus(ctx context.Context, cart *models.Cart) error
+	AddItemToCart(ctx context.Context, cart *models.Cart, item models.CartItem) error
+	UpdateCartItemQuantity(ctx context.Context, cart *models.Cart, productID primitive.ObjectID, quantity int, types string, item models.CartItem) error
+	RemoveFromCart(ctx context.Context, cart *models.Cart, productID primitive.ObjectID, variation *models.VariationSelection) error
+	SaveForLater(ctx context.Context, cart *models.Cart, productID primitive.ObjectID, variation *models.VariationSelection) error
+	MoveToCart(ctx context.Context, cart *models.Cart, item models.CartItem) error
 	ClearCart(ctx context.Context, teacherID string) error
-	GetCartHistoryByTeacher(ctx context.Context, teacherID string) ([]bson.M, error)
+	EmptyCart(ctx context.Context, cart *models.Cart) error
+	GetCartHistoryByTeacher(ctx context.Context, teacherID string, filter models.CartHistoryFilter) ([]bson.M, error)
 }
 
+// defaultCartFilter - Matches the default carts. Carts stored before named carts existed have no
+// "named" field and are default carts.
+var defaultCartFilter = bson.M{"$ne": true}
+
 type cartRepository struct {
 	collection        *mongo.Collection
 	collectionHistory *mongo.Collection
@@ -66,7 +85,7 @@ func (r *cartRepository) GetCartByTeacherStudent(ctx context.Context, teacherID
 
 	var cart models.Cart
 
-	filter := bson.M{"teacher_id": teacherID, "student_id": studentID}
+	filter := bson.M{"teacher_id": teacherID, "student_id": studentID, "named": defaultCartFilter}
 
 	err := r.collection.FindOne(ctx, filter).Decode(&cart)
 
@@ -95,20 +114,195 @@ func (r *cartRepository) GetCartByTeacherStudent(ctx context.Context, teacherID
 
 }
 
+// GetCartByID - Returns the teacher's cart, or nil when it does not exist or belongs to someone else.
+func (r *cartRepository) GetCartByID(ctx context.Context, teacherID string, cartID primitive.ObjectID) (*models.Cart, error) {
+
+	var cart models.Cart
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": cartID, "teacher_id": teacherID}).Decode(&cart)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &cart, nil
+}
+
+// FindCart - Returns the cart whoever it belongs to, or nil when it does not exist.
+func (r *cartRepository) FindCart(ctx context.Context, cartID primitive.ObjectID) (*models.Cart, error) {
+
+	var cart models.Cart
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": cartID}).Decode(&cart)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &cart, nil
+}
+
+// GetCartsByStatus - Lists the carts of all teachers in the approval status, oldest change first.
+// Carts without a status are drafts.
+func (r *cartRepository) GetCartsByStatus(ctx context.Context, status string) ([]models.Cart, error) {
+
+	filter := bson.M{"status": status}
+	if status == models.CartStatusDraft {
+		filter = bson.M{"status": bson.M{"$in": bson.A{nil, models.CartStatusDraft}}}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "update_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	carts := []models.Cart{}
+	if err := cursor.All(ctx, &carts); err != nil {
+		return nil, err
+	}
+
+	return carts, nil
+}
+
+// GetNamedCarts - Lists the teacher's named carts, for one student when studentID is set.
+func (r *cartRepository) GetNamedCarts(ctx context.Context, teacherID string, studentID string) ([]models.Cart, error) {
+
+	filter := bson.M{"teacher_id": teacherID, "named": true}
+	if studentID != "" {
+		filter["student_id"] = studentID
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "create_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	carts := []models.Cart{}
+	if err := cursor.All(ctx, &carts); err != nil {
+		return nil, err
+	}
+
+	return carts, nil
+}
+
+func (r *cartRepository) CreateCart(ctx context.Context, cart *models.Cart) error {
+
+	cart.ID = primitive.NewObjectID()
+	cart.Items = []models.CartItem{}
+	cart.CreateAt = time.Now()
+	cart.UpdateAt = cart.CreateAt
+
+	_, err := r.collection.InsertOne(ctx, cart)
+
+	return err
+}
+
+func (r *cartRepository) RenameCart(ctx context.Context, cart *models.Cart, name string) error {
+
+	cart.Name = name
+	cart.UpdateAt = time.Now()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": cart.ID}, bson.M{
+		"$set": bson.M{"name": cart.Name, "update_at": cart.UpdateAt},
+	})
+
+	return err
+}
+
+// GetCartsByStudent - Returns the default and named carts the teacher keeps for the student,
+// without creating a default cart when there is none.
+func (r *cartRepository) GetCartsByStudent(ctx context.Context, teacherID string, studentID string) ([]models.Cart, error) {
+
+	opts := options.Find().SetSort(bson.D{{Key: "create_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"teacher_id": teacherID, "student_id": studentID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	carts := []models.Cart{}
+	if err := cursor.All(ctx, &carts); err != nil {
+		return nil, err
+	}
+
+	return carts, nil
+}
+
+// ReassignCart - Hands a named cart over to another teacher under the given name.
+func (r *cartRepository) ReassignCart(ctx context.Context, cart *models.Cart, teacherID string, name string) error {
+
+	cart.TeacherID = teacherID
+	cart.Name = name
+	cart.UpdateAt = time.Now()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": cart.ID}, bson.M{
+		"$set": bson.M{"teacher_id": cart.TeacherID, "name": cart.Name, "update_at": cart.UpdateAt},
+	})
+
+	return err
+}
+
+// DeleteCart - Deletes the cart only while it is unchanged since it was read.
+func (r *cartRepository) DeleteCart(ctx context.Context, cart *models.Cart) error {
+
+	result, err := r.collection.DeleteOne(ctx, cartVersionFilter(cart))
+
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return models.NewServiceError(http.StatusConflict, models.ErrCartModified, "cart was changed by another request; reload it and try again", nil)
+	}
+
+	return nil
+}
+
 func (r *cartRepository) GetCartByTeacher(ctx context.Context, teacherID string) ([]bson.M, error) {
 
 	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: bson.M{"teacher_id": teacherID}}},
+		{{Key: "$match", Value: bson.M{"teacher_id": teacherID, "named": defaultCartFilter}}},
 		{{
 			Key: "$group", Value: bson.D{
 				{Key: "_id", Value: "$student_id"},
 				{Key: "items", Value: bson.M{"$first": "$items"}},
+				{Key: "saved_items", Value: bson.M{"$first": "$saved_items"}},
 				{Key: "total_price_store", Value: bson.M{
 					"$sum": "$total_price_store",
 				}},
 				{Key: "total_price_service", Value: bson.M{
 					"$sum": "$total_price_service",
 				}},
+				{Key: "total_savings_store", Value: bson.M{
+					"$sum": "$total_savings_store",
+				}},
+				{Key: "total_savings_service", Value: bson.M{
+					"$sum": "$total_savings_service",
+				}},
+				{Key: "total_planned_uses", Value: bson.M{
+					"$sum": "$total_planned_uses",
+				}},
+				{Key: "total_minimum_usage_time", Value: bson.M{
+					"$sum": "$total_minimum_usage_time",
+				}},
+				{Key: "total_maximum_usage_time", Value: bson.M{
+					"$sum": "$total_maximum_usage_time",
+				}},
+				{Key: "warnings", Value: bson.M{"$first": "$warnings"}},
+				{Key: "cart_id", Value: bson.M{"$first": "$_id"}},
+				{Key: "status", Value: bson.M{"$first": "$status"}},
+				{Key: "approval", Value: bson.M{"$first": "$approval"}},
 				{Key: "create_at", Value: bson.M{"$first": "$create_at"}},
 			},
 		}},
@@ -116,13 +310,27 @@ func (r *cartRepository) GetCartByTeacher(ctx context.Context, teacherID string)
 			Key: "$project", Value: bson.D{
 				{Key: "_id", Value: 1},
 				{Key: "items", Value: 1},
+				{Key: "saved_items", Value: 1},
 				{Key: "create_at", Value: 1}, // Include create_at field here
+				{Key: "total_planned_uses", Value: 1},
+				{Key: "total_minimum_usage_time", Value: 1},
+				{Key: "total_maximum_usage_time", Value: 1},
+				{Key: "warnings", Value: 1},
+				{Key: "cart_id", Value: 1},
+				{Key: "status", Value: bson.M{"$ifNull": bson.A{"$status", models.CartStatusDraft}}},
+				{Key: "approval", Value: 1},
 				{Key: "total_price_store", Value: bson.M{
 					"$round": bson.A{"$total_price_store", 2},
 				}},
 				{Key: "total_price_service", Value: bson.M{
 					"$round": bson.A{"$total_price_service", 2},
 				}},
+				{Key: "total_savings_store", Value: bson.M{
+					"$round": bson.A{"$total_savings_store", 2},
+				}},
+				{Key: "total_savings_service", Value: bson.M{
+					"$round": bson.A{"$total_savings_service", 2},
+				}},
 			},
 		}},
 		{{Key: "$sort", Value: bson.D{{Key: "create_at", Value: -1}}}},
@@ -142,43 +350,107 @@ func (r *cartRepository) GetCartByTeacher(ctx context.Context, teacherID string)
 	return results, nil
 }
 
+// GetCartsByTeacher - Returns the teacher's default carts, which are checked out together.
+func (r *cartRepository) GetCartsByTeacher(ctx context.Context, teacherID string) ([]models.Cart, error) {
+
+	cursor, err := r.collection.Find(ctx, bson.M{"teacher_id": teacherID, "named": defaultCartFilter})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var carts []models.Cart
+	if err := cursor.All(ctx, &carts); err != nil {
+		return nil, err
+	}
+
+	return carts, nil
+}
+
+// UpdateCart - Saves the cart's lines and totals. The write only applies while the stored cart is
+// unchanged since it was read, so that two requests editing the same cart cannot silently drop each
+// other's lines; otherwise the cart is left alone and a conflict is returned.
 func (r *cartRepository) UpdateCart(ctx context.Context, cart *models.Cart) error {
 
-	cart.UpdateAt = time.Now()
+	filter := cartVersionFilter(cart)
 
-	filter := bson.M{"_id": cart.ID}
+	updateAt := time.Now()
 
 	update := bson.M{
 		"$set": bson.M{
-			"items":               cart.Items,
-			"total_price_store":   cart.TotalPriceStore,
-			"total_price_service": cart.TotalPriceService,
-			"update_at":           cart.UpdateAt,
+			"items":                    cart.Items,
+			"saved_items":              cart.SavedItems,
+			"total_price_store":        cart.TotalPriceStore,
+			"total_price_service":      cart.TotalPriceService,
+			"total_savings_store":      cart.TotalSavingsStore,
+			"total_savings_service":    cart.TotalSavingsService,
+			"total_planned_uses":       cart.TotalPlannedUses,
+			"total_minimum_usage_time": cart.TotalMinimumUsageTime,
+			"total_maximum_usage_time": cart.TotalMaximumUsageTime,
+			"warnings":                 cart.Warnings,
+			"update_at":                updateAt,
 		},
 	}
 
-	_, err := r.collection.UpdateOne(ctx, filter, update)
+	result, err := r.collection.UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return err
 	}
 
+	if result.MatchedCount == 0 {
+		return models.NewServiceError(http.StatusConflict, models.ErrCartModified, "cart was changed by another request; reload it and try again", nil)
+	}
+
+	cart.UpdateAt = updateAt
+
 	return nil
 }
 
-func (r *cartRepository) AddItemToCart(ctx context.Context, teacherID string, studentID string, item models.CartItem) error {
+// UpdateCartStatus - Saves the cart's approval status and its latest submission. Like UpdateCart,
+// it only applies to the cart as it was read, so a decision cannot land on a cart that was edited,
+// resubmitted or decided on in the meantime.
+func (r *cartRepository) UpdateCartStatus(ctx context.Context, cart *models.Cart) error {
+
+	updateAt := time.Now()
 
-	cart, err := r.GetCartByTeacherStudent(ctx, teacherID, studentID)
+	result, err := r.collection.UpdateOne(ctx, cartVersionFilter(cart), bson.M{
+		"$set": bson.M{"status": cart.Status, "approval": cart.Approval, "update_at": updateAt},
+	})
 
 	if err != nil {
 		return err
 	}
 
+	if result.MatchedCount == 0 {
+		return models.NewServiceError(http.StatusConflict, models.ErrCartModified, "cart was changed by another request; reload it and try again", nil)
+	}
+
+	cart.UpdateAt = updateAt
+
+	return nil
+}
+
+// cartVersionFilter - Matches the cart only while it is unchanged since it was read.
+func cartVersionFilter(cart *models.Cart) bson.M {
+
+	filter := bson.M{"_id": cart.ID, "update_at": cart.UpdateAt}
+	if cart.UpdateAt.IsZero() {
+		// Carts saved before update_at was recorded
+		filter["update_at"] = bson.M{"$in": bson.A{nil, cart.UpdateAt}}
+	}
+
+	return filter
+}
+
+func (r *cartRepository) AddItemToCart(ctx context.Context, cart *models.Cart, item models.CartItem) error {
+
 	found := false
 
 	for i, existingItem := range cart.Items {
-		if existingItem.ProductID == item.ProductID {
+		if existingItem.SameLine(item.ProductID, item.Variation) {
 			cart.Items[i].Quantity += item.Quantity
+			cart.Items[i].Refresh(item)
 			found = true
 			break
 		}
@@ -191,119 +463,54 @@ func (r *cartRepository) AddItemToCart(ctx context.Context, teacherID string, st
 	return r.UpdateCartTotalPrice(ctx, cart)	
 }
 
-func (r *cartRepository) UpdateCartItemQuantity(ctx context.Context, teacherID string, studentID string, productID primitive.ObjectID, quantity int, types string, item models.CartItem) error {
+func (r *cartRepository) UpdateCartItemQuantity(ctx context.Context, cart *models.Cart, productID primitive.ObjectID, quantity int, types string, item models.CartItem) error {
 	if types == "increase" {
-		return r.IncreaseCartItemQuantity(ctx, teacherID, studentID, productID, item)
+		return r.IncreaseCartItemQuantity(ctx, cart, productID, item)
 	} else {
-		return r.DecreaseCartItemQuantity(ctx, teacherID, studentID, productID)
+		return r.DecreaseCartItemQuantity(ctx, cart, productID, item.Variation)
 	}
 }
 
-func (r *cartRepository) IncreaseCartItemQuantity(ctx context.Context, teacherID string, studentID string, productID primitive.ObjectID, item models.CartItem) error {
-	cart, err := r.GetCartByTeacherStudent(ctx, teacherID, studentID)
-	if err != nil {
-		return err
-	}
+func (r *cartRepository) IncreaseCartItemQuantity(ctx context.Context, cart *models.Cart, productID primitive.ObjectID, item models.CartItem) error {
+	added := 1
 	found := false
-	for i, item := range cart.Items {
-		if item.ProductID.Hex() == productID.Hex() {
+	for i, existing := range cart.Items {
+		if existing.SameLine(productID, item.Variation) {
 			found = true
 			cart.Items[i].Quantity += 1
-
-			history := models.CartHistory{
-				TeacherID:  teacherID,
-				StudentID:  studentID,
-				ProductID:  productID,
-				EventType:  "add",
-				Quantity:   1,
-				OcccuredOn: time.Now(),
-			}
-
-			_, err := r.collectionHistory.InsertOne(ctx, history)
-			if err != nil {
-				return err
-			}
+			cart.Items[i].Refresh(item)
+			break
 		}
 	}
 
 	if !found {
 		cart.Items = append(cart.Items, item)
+		added = item.Quantity
+	}
 
-		history := models.CartHistory{
-			TeacherID:  teacherID,
-			StudentID:  studentID,
-			ProductID:  productID,
-			EventType:  "add",
-			Quantity:   1,
-			OcccuredOn: time.Now(),
-		}
-
-		_, err = r.collectionHistory.InsertOne(ctx, history)
-		if err != nil {
-			return err
-		}
+	_, err := r.collectionHistory.InsertOne(ctx, newCartHistory(ctx, cart.TeacherID, cart.StudentID, item, "add", added))
+	if err != nil {
+		return err
 	}
 
 	return r.UpdateCartTotalPrice(ctx, cart)
 }
 
-func (r *cartRepository) DecreaseCartItemQuantity(ctx context.Context, teacherID string, studentID string, productID primitive.ObjectID) error {
-	cart, err := r.GetCartByTeacherStudent(ctx, teacherID, studentID)
-	if err != nil {
-		return err
-	}
+func (r *cartRepository) DecreaseCartItemQuantity(ctx context.Context, cart *models.Cart, productID primitive.ObjectID, variation *models.VariationSelection) error {
 	found := false
 	for i, item := range cart.Items {
-		if item.ProductID.Hex() == productID.Hex() {
+		if item.SameLine(productID, variation) {
 			found = true
 			if item.Quantity > 1 {
 				cart.Items[i].Quantity -= 1
-
-				history := models.CartHistory{
-					TeacherID:  teacherID,
-					StudentID:  studentID,
-					ProductID:  productID,
-					EventType:  "remove",
-					Quantity:   1,
-					OcccuredOn: time.Now(),
-				}
-
-				_, err := r.collectionHistory.InsertOne(ctx, history)
-				if err != nil {
-					return err
-				}
 			} else {
-				filter := bson.M{"teacher_id": teacherID, "student_id": studentID}
-				update := bson.M{
-					"$pull": bson.M{
-						"items": bson.M{"product_id": productID},
-					},
-				}
-
-				_, err := r.collection.UpdateOne(ctx, filter, update)
-				if err != nil {
-					return err
-				}
-
-				cart, err = r.GetCartByTeacherStudent(ctx, teacherID, studentID)
-				if err != nil {
-					return err
-				}
-
-				// Ghi lịch sử xóa sản phẩm
-				history := models.CartHistory{
-					TeacherID:  teacherID,
-					StudentID:  studentID,
-					ProductID:  productID,
-					EventType:  "remove",
-					Quantity:   1,
-					OcccuredOn: time.Now(),
-				}
-
-				_, err = r.collectionHistory.InsertOne(ctx, history)
-				if err != nil {
-					return err
-				}
+				// Xóa dòng sản phẩm khi số lượng về 0
+				cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+			}
+
+			_, err := r.collectionHistory.InsertOne(ctx, newCartHistory(ctx, cart.TeacherID, cart.StudentID, item, "remove", 1))
+			if err != nil {
+				return err
 			}
 			break
 		}
@@ -316,17 +523,13 @@ func (r *cartRepository) DecreaseCartItemQuantity(ctx context.Context, teacherID
 	return r.UpdateCartTotalPrice(ctx, cart)
 }
 
-func (r *cartRepository) RemoveFromCart(ctx context.Context, teacherID string, studentID string, productID primitive.ObjectID) error {
-
-	cart, err := r.GetCartByTeacherStudent(ctx, teacherID, studentID)
-	if err != nil {
-		return err
-	}
+func (r *cartRepository) RemoveFromCart(ctx context.Context, cart *models.Cart, productID primitive.ObjectID, variation *models.VariationSelection) error {
 
 	found := false
 
-	for _, item := range cart.Items {
-		if item.ProductID == productID {
+	for i, item := range cart.Items {
+		if item.SameLine(productID, variation) {
+			cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
 			found = true
 			break
 		}
@@ -336,40 +539,58 @@ func (r *cartRepository) RemoveFromCart(ctx context.Context, teacherID string, s
 		return fmt.Errorf("product not found in cart")
 	}
 
-	filter := bson.M{
-		"teacher_id": teacherID,
-		"student_id": studentID,
+	return r.UpdateCartTotalPrice(ctx, cart)
+
+}
+
+// SaveForLater - Moves an active line to the saved list, merging it with a saved line for the same product and variation.
+func (r *cartRepository) SaveForLater(ctx context.Context, cart *models.Cart, productID primitive.ObjectID, variation *models.VariationSelection) error {
+
+	var saved *models.CartItem
+
+	for i, item := range cart.Items {
+		if item.SameLine(productID, variation) {
+			saved = &item
+			cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+			break
+		}
 	}
 
-	update := bson.M{
-		"$pull": bson.M{
-			"items": bson.M{
-				"product_id": productID,
-			},
-		},
+	if saved == nil {
+		return fmt.Errorf("product not found in cart")
 	}
 
-	_, err = r.collection.UpdateOne(ctx, filter, update)
+	cart.SavedItems = models.MergeCartLine(cart.SavedItems, *saved)
 
-	if err != nil {
-		return err
-	}
+	return r.UpdateCartTotalPrice(ctx, cart)
+}
 
-	cart, err = r.GetCartByTeacherStudent(ctx, teacherID, studentID)
+// MoveToCart - Moves a saved line back to the active items. The given item carries the line's current pricing.
+func (r *cartRepository) MoveToCart(ctx context.Context, cart *models.Cart, item models.CartItem) error {
 
-	if err != nil {
-		return err
+	found := false
+
+	for i, saved := range cart.SavedItems {
+		if saved.SameLine(item.ProductID, item.Variation) {
+			cart.SavedItems = append(cart.SavedItems[:i], cart.SavedItems[i+1:]...)
+			found = true
+			break
+		}
 	}
 
-	return r.UpdateCartTotalPrice(ctx, cart)
+	if !found {
+		return fmt.Errorf("product not found in saved items")
+	}
 
+	cart.Items = models.MergeCartLine(cart.Items, item)
+
+	return r.UpdateCartTotalPrice(ctx, cart)
 }
 
+// ClearCart - Empties the teacher's default carts. Saved items are kept.
 func (r *cartRepository) ClearCart(ctx context.Context, teacherID string) error {
 
-	var cart models.Cart
-
-	filter := bson.M{"teacher_id": teacherID}
+	filter := bson.M{"teacher_id": teacherID, "named": defaultCartFilter}
 
 	cursor, err := r.collection.Find(ctx, filter)
 	if err != nil {
@@ -378,15 +599,11 @@ func (r *cartRepository) ClearCart(ctx context.Context, teacherID string) error
 	defer cursor.Close(ctx)
 
 	for cursor.Next(ctx) {
+		var cart models.Cart
 		if err := cursor.Decode(&cart); err != nil {
 			return err
 		}
-		cart.Items = []models.CartItem{}
-		cart.TotalPriceStore = 0
-		cart.TotalPriceService = 0
-		cart.UpdateAt = time.Now()
-
-		if err := r.UpdateCart(ctx, &cart); err != nil {
+		if err := r.EmptyCart(ctx, &cart); err != nil {
 			return err
 		}
 	}
@@ -394,22 +611,25 @@ func (r *cartRepository) ClearCart(ctx context.Context, teacherID string) error
 	return nil
 }
 
+// EmptyCart - Empties a single cart. Saved items are kept.
+func (r *cartRepository) EmptyCart(ctx context.Context, cart *models.Cart) error {
+
+	cart.Items = []models.CartItem{}
+	cart.Warnings = nil
+	cart.CalculateTotals()
+
+	return r.UpdateCart(ctx, cart)
+}
+
 func (r *cartRepository) UpdateCartTotalPrice(ctx context.Context, cart *models.Cart) error {
-	totalPriceStore := 0.0
-	totalPriceService := 0.0
-	for _, item := range cart.Items {
-		totalPriceStore += item.PriceStore * float64(item.Quantity)
-		totalPriceService += item.PriceService * float64(item.Quantity)
-	}
-	cart.TotalPriceStore = math.Round(totalPriceStore*100) / 100
-	cart.TotalPriceService = math.Round(totalPriceService*100) / 100
+	cart.CalculateTotals()
 	return r.UpdateCart(ctx, cart)
 }
 
-func (r *cartRepository) GetCartHistoryByTeacher(ctx context.Context, teacherID string) ([]bson.M, error) {
+func (r *cartRepository) GetCartHistoryByTeacher(ctx context.Context, teacherID string, filter models.CartHistoryFilter) ([]bson.M, error) {
 	pipeline := mongo.Pipeline{
-		// Lọc theo teacherID
-		bson.D{{Key: "$match", Value: bson.D{{Key: "teacher_id", Value: teacherID}}}},
+		// Lọc theo teacherID và các bộ lọc người thực hiện
+		bson.D{{Key: "$match", Value: cartHistoryMatch(teacherID, filter)}},
 
 		// Nhóm theo teacherID và studentID
 		bson.D{{Key: "$group", Value: bson.D{
@@ -419,9 +639,15 @@ func (r *cartRepository) GetCartHistoryByTeacher(ctx context.Context, teacherID
 			}},
 			{Key: "products", Value: bson.D{{Key: "$push", Value: bson.D{
 				{Key: "product_id", Value: "$product_id"},
+				{Key: "variation", Value: "$variation"},
 				{Key: "event_type", Value: "$event_type"},
 				{Key: "quantity", Value: "$quantity"},
-				{Key: "occurred_on", Value: "$occured_on"},
+				{Key: "price_store", Value: "$price_store"},
+				{Key: "price_service", Value: "$price_service"},
+				{Key: "occurred_on", Value: "$occcured_on"},
+				{Key: "actor", Value: "$actor"},
+				{Key: "cart_id", Value: "$cart_id"},
+				{Key: "comment", Value: "$comment"},
 			}}}},
 		}}},
 
@@ -443,7 +669,7 @@ func (r *cartRepository) GetCartHistoryByTeacher(ctx context.Context, teacherID
 		}}},
 	}
 
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	cursor, err := r.collectionHistory.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
@@ -456,3 +682,24 @@ func (r *cartRepository) GetCartHistoryByTeacher(ctx context.Context, teacherID
 
 	return results, nil
 }
+
+// cartHistoryMatch - The teacher's history, narrowed to the actor fields set in the filter.
+func cartHistoryMatch(teacherID string, filter models.CartHistoryFilter) bson.D {
+	match := bson.D{{Key: "teacher_id", Value: teacherID}}
+	if filter.ActorID != "" {
+		match = append(match, bson.E{Key: "actor.user_id", Value: filter.ActorID})
+	}
+	if filter.ActorRole != "" {
+		match = append(match, bson.E{Key: "actor.roles", Value: filter.ActorRole})
+	}
+	if filter.ClientIP != "" {
+		match = append(match, bson.E{Key: "actor.client_ip", Value: filter.ClientIP})
+	}
+	if filter.UserAgent != "" {
+		match = append(match, bson.E{Key: "actor.user_agent", Value: filter.UserAgent})
+	}
+	if filter.RequestID != "" {
+		match = append(match, bson.E{Key: "actor.request_id", Value: filter.RequestID})
+	}
+	return match
+}
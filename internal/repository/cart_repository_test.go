@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"reflect"
+	"store/internal/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCartVersionFilter(t *testing.T) {
+	id := primitive.NewObjectID()
+	updateAt := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		cart models.Cart
+		want bson.M
+	}{
+		{
+			name: "cart as read",
+			cart: models.Cart{ID: id, UpdateAt: updateAt, Status: models.CartStatusSubmitted},
+			want: bson.M{"_id": id, "update_at": updateAt},
+		},
+		{
+			name: "cart saved before update_at was recorded",
+			cart: models.Cart{ID: id},
+			want: bson.M{"_id": id, "update_at": bson.M{"$in": bson.A{nil, time.Time{}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cartVersionFilter(&tt.cart); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cartVersionFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCartHistoryMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter models.CartHistoryFilter
+		want   bson.D
+	}{
+		{
+			name: "teacher only",
+			want: bson.D{{Key: "teacher_id", Value: "t1"}},
+		},
+		{
+			name:   "actor and role",
+			filter: models.CartHistoryFilter{ActorID: "admin-1", ActorRole: "admin"},
+			want: bson.D{
+				{Key: "teacher_id", Value: "t1"},
+				{Key: "actor.user_id", Value: "admin-1"},
+				{Key: "actor.roles", Value: "admin"},
+			},
+		},
+		{
+			name:   "request origin",
+			filter: models.CartHistoryFilter{ClientIP: "10.0.0.7", UserAgent: "curl/8.0", RequestID: "req-1"},
+			want: bson.D{
+				{Key: "teacher_id", Value: "t1"},
+				{Key: "actor.client_ip", Value: "10.0.0.7"},
+				{Key: "actor.user_agent", Value: "curl/8.0"},
+				{Key: "actor.request_id", Value: "req-1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cartHistoryMatch("t1", tt.filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cartHistoryMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
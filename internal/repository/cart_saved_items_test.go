@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"store/internal/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestSavedItems(t *testing.T) {
+	pen := models.CartItem{ProductID: primitive.NewObjectID(), Quantity: 2, PriceStore: 1.5}
+	book := models.CartItem{ProductID: primitive.NewObjectID(), Quantity: 1, PriceStore: 5}
+	repricedPen := pen
+	repricedPen.PriceStore = 1
+
+	tests := []struct {
+		name      string
+		cart      models.Cart
+		move      func(r *cartRepository, cart *models.Cart) error
+		wantErr   bool
+		wantItems []models.CartItem
+		wantSaved []models.CartItem
+		wantTotal float64
+	}{
+		{
+			name: "save for later",
+			cart: models.Cart{Items: []models.CartItem{pen, book}},
+			move: func(r *cartRepository, cart *models.Cart) error {
+				return r.SaveForLater(context.Background(), cart, pen.ProductID, nil)
+			},
+			wantItems: []models.CartItem{book},
+			wantSaved: []models.CartItem{pen},
+			wantTotal: 5,
+		},
+		{
+			name: "save merges into the saved line",
+			cart: models.Cart{Items: []models.CartItem{pen}, SavedItems: []models.CartItem{{ProductID: pen.ProductID, Quantity: 1, PriceStore: 1.5}}},
+			move: func(r *cartRepository, cart *models.Cart) error {
+				return r.SaveForLater(context.Background(), cart, pen.ProductID, nil)
+			},
+			wantItems: []models.CartItem{},
+			wantSaved: []models.CartItem{{ProductID: pen.ProductID, Quantity: 3, PriceStore: 1.5}},
+		},
+		{
+			name: "move to cart at the current price",
+			cart: models.Cart{Items: []models.CartItem{book}, SavedItems: []models.CartItem{pen}},
+			move: func(r *cartRepository, cart *models.Cart) error {
+				return r.MoveToCart(context.Background(), cart, repricedPen)
+			},
+			wantItems: []models.CartItem{book, repricedPen},
+			wantSaved: []models.CartItem{},
+			wantTotal: 7,
+		},
+		{
+			name: "line not in the cart",
+			cart: models.Cart{Items: []models.CartItem{book}},
+			move: func(r *cartRepository, cart *models.Cart) error {
+				return r.SaveForLater(context.Background(), cart, pen.ProductID, nil)
+			},
+			wantErr:   true,
+			wantItems: []models.CartItem{book},
+		},
+		{
+			name: "line not saved",
+			cart: models.Cart{Items: []models.CartItem{book}},
+			move: func(r *cartRepository, cart *models.Cart) error {
+				return r.MoveToCart(context.Background(), cart, repricedPen)
+			},
+			wantErr:   true,
+			wantItems: []models.CartItem{book},
+		},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+			r := &cartRepository{collection: mt.Coll, collectionHistory: mt.Coll}
+			cart := tt.cart
+			cart.ID = primitive.NewObjectID()
+			cart.Items = append([]models.CartItem{}, tt.cart.Items...)
+			cart.SavedItems = append([]models.CartItem{}, tt.cart.SavedItems...)
+
+			err := tt.move(r, &cart)
+			if (err != nil) != tt.wantErr {
+				mt.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if event := mt.GetStartedEvent(); event != nil {
+					mt.Errorf("sent %s for a line that is not there", event.CommandName)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(cart.Items, tt.wantItems) {
+				mt.Errorf("items = %+v, want %+v", cart.Items, tt.wantItems)
+			}
+			if !reflect.DeepEqual(cart.SavedItems, tt.wantSaved) {
+				mt.Errorf("saved items = %+v, want %+v", cart.SavedItems, tt.wantSaved)
+			}
+			if cart.TotalPriceStore != tt.wantTotal {
+				mt.Errorf("store total = %v, want %v, without the saved items", cart.TotalPriceStore, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -21,16 +21,9 @@ func NewCartHistoryRepository(collectionHistory *mongo.Collection, collectionCar
 	}
 }
 
-func (r *CartHistoryRepository) AddCartHistory(ctx context.Context, teacherID string, studentID string, productID primitive.ObjectID, eventType string, quantity int) error {
+func (r *CartHistoryRepository) AddCartHistory(ctx context.Context, teacherID string, studentID string, item models.CartItem, eventType string, quantity int) error {
 
-	history := models.CartHistory{
-		TeacherID: teacherID,
-		StudentID: studentID,
-		ProductID:  productID,
-		EventType:  eventType,
-		OcccuredOn: time.Now(),
-		Quantity:   quantity,
-	}
+	history := newCartHistory(ctx, teacherID, studentID, item, eventType, quantity)
 
 	_, err := r.collectionHistory.InsertOne(ctx, history)
 
@@ -41,39 +34,24 @@ func (r *CartHistoryRepository) AddCartHistory(ctx context.Context, teacherID st
 	return nil
 }
 
-func (r *CartHistoryRepository) AddAllCartHistory(ctx context.Context, teacherID string) error {
-	var cart models.Cart
+// AddAllCartHistory - Records an event of the given type, "order" or "clear", for every line in the
+// teacher's default carts.
+func (r *CartHistoryRepository) AddAllCartHistory(ctx context.Context, teacherID string, eventType string) error {
 
-	cursor, err := r.collectionCart.Find(ctx, bson.M{"teacher_id": teacherID})
+	cursor, err := r.collectionCart.Find(ctx, bson.M{"teacher_id": teacherID, "named": bson.M{"$ne": true}})
 	if err != nil {
 		return err
 	}
 	defer cursor.Close(ctx)
 
 	for cursor.Next(ctx) {
+		var cart models.Cart
 		if err := cursor.Decode(&cart); err != nil {
 			return err
 		}
 
-		var historyRecords []interface{}
-	
-		for _, item := range cart.Items {
-			historyRecord := models.CartHistory{
-				TeacherID:  teacherID,
-				StudentID:  cart.StudentID,
-				ProductID:  item.ProductID,
-				EventType:  "order",
-				Quantity:   item.Quantity,
-				OcccuredOn: time.Now(),
-			}
-			historyRecords = append(historyRecords, historyRecord)
-		}
-	
-		if len(historyRecords) > 0 {
-			_, err := r.collectionHistory.InsertMany(ctx, historyRecords)
-			if err != nil {
-				return err
-			}
+		if err := r.addLinesHistory(ctx, cart, eventType); err != nil {
+			return err
 		}
 
 	}
@@ -84,3 +62,168 @@ func (r *CartHistoryRepository) AddAllCartHistory(ctx context.Context, teacherID
 
 	return nil
 }
+
+// AddOrderHistory - Records "order" events for every line in one cart.
+func (r *CartHistoryRepository) AddOrderHistory(ctx context.Context, cart models.Cart) error {
+	return r.addLinesHistory(ctx, cart, "order")
+}
+
+func (r *CartHistoryRepository) addLinesHistory(ctx context.Context, cart models.Cart, eventType string) error {
+
+	historyRecords := cartLinesHistory(ctx, cart, eventType)
+
+	if len(historyRecords) == 0 {
+		return nil
+	}
+
+	_, err := r.collectionHistory.InsertMany(ctx, historyRecords)
+
+	return err
+}
+
+// AddCartEvent - Records an approval workflow event for a whole cart. The quantity is the number of
+// units in the cart at the time.
+func (r *CartHistoryRepository) AddCartEvent(ctx context.Context, cart models.Cart, eventType string, comment string) error {
+
+	quantity := 0
+	for _, item := range cart.Items {
+		quantity += item.Quantity
+	}
+
+	history := models.CartHistory{
+		TeacherID:  cart.TeacherID,
+		StudentID:  cart.StudentID,
+		CartID:     &cart.ID,
+		EventType:  eventType,
+		Quantity:   quantity,
+		Comment:    comment,
+		OcccuredOn: time.Now(),
+		Actor:      models.ActorFromContext(ctx),
+	}
+
+	_, err := r.collectionHistory.InsertOne(ctx, history)
+
+	return err
+}
+
+// CountOrderedQuantity - Sums the quantity of a product recorded in "order" events for a teacher,
+// narrowed to one student when studentID is set.
+func (r *CartHistoryRepository) CountOrderedQuantity(ctx context.Context, teacherID string, studentID string, productID primitive.ObjectID) (int, error) {
+
+	cursor, err := r.collectionHistory.Aggregate(ctx, orderedQuantityPipeline(teacherID, studentID, productID))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Quantity int `bson:"quantity"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return 0, err
+	}
+
+	if len(results) == 0 {
+		return 0, nil
+	}
+
+	return results[0].Quantity, nil
+}
+
+// SumOrderedSpend - Sums the store and service prices of the lines recorded in "order" events from
+// from up to to, for a teacher or for a student, whichever is set. Events without prices count as zero,
+// and emptied carts, recorded as "clear" events, do not count.
+func (r *CartHistoryRepository) SumOrderedSpend(ctx context.Context, teacherID string, studentID string, from time.Time, to time.Time) (float64, float64, error) {
+
+	match := bson.M{
+		"event_type":  "order",
+		"occcured_on": bson.M{"$gte": from, "$lt": to},
+	}
+	if teacherID != "" {
+		match["teacher_id"] = teacherID
+	}
+	if studentID != "" {
+		match["student_id"] = studentID
+	}
+
+	lineTotal := func(field string) bson.M {
+		return bson.M{"$sum": bson.M{"$multiply": bson.A{bson.M{"$ifNull": bson.A{field, 0}}, "$quantity"}}}
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": match},
+		bson.M{"$group": bson.M{
+			"_id":     nil,
+			"store":   lineTotal("$price_store"),
+			"service": lineTotal("$price_service"),
+		}},
+	}
+
+	cursor, err := r.collectionHistory.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Store   float64 `bson:"store"`
+		Service float64 `bson:"service"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return 0, 0, err
+	}
+
+	if len(results) == 0 {
+		return 0, 0, nil
+	}
+
+	return results[0].Store, results[0].Service, nil
+}
+
+// orderedQuantityPipeline - Sums the quantity of a product over "order" events only; lines dropped by
+// clearing a cart are recorded as "clear" events and never count as purchased.
+func orderedQuantityPipeline(teacherID string, studentID string, productID primitive.ObjectID) bson.A {
+
+	match := bson.M{
+		"teacher_id": teacherID,
+		"product_id": productID,
+		"event_type": "order",
+	}
+	if studentID != "" {
+		match["student_id"] = studentID
+	}
+
+	return bson.A{
+		bson.M{"$match": match},
+		bson.M{"$group": bson.M{"_id": nil, "quantity": bson.M{"$sum": "$quantity"}}},
+	}
+}
+
+// cartLinesHistory - Builds one eventType record per line of the cart.
+func cartLinesHistory(ctx context.Context, cart models.Cart, eventType string) []interface{} {
+
+	var historyRecords []interface{}
+
+	for _, item := range cart.Items {
+		historyRecord := newCartHistory(ctx, cart.TeacherID, cart.StudentID, item, eventType, item.Quantity)
+		historyRecords = append(historyRecords, historyRecord)
+	}
+
+	return historyRecords
+}
+
+// newCartHistory - Builds a history record for a cart line, attributed to the actor of the request.
+func newCartHistory(ctx context.Context, teacherID string, studentID string, item models.CartItem, eventType string, quantity int) models.CartHistory {
+	return models.CartHistory{
+		TeacherID:    teacherID,
+		StudentID:    studentID,
+		ProductID:    item.ProductID,
+		Variation:    item.Variation,
+		EventType:    eventType,
+		Quantity:     quantity,
+		PriceStore:   item.PriceStore,
+		PriceService: item.PriceService,
+		OcccuredOn:   time.Now(),
+		Actor:        models.ActorFromContext(ctx),
+	}
+}
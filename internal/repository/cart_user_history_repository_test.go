@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"store/internal/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCartLinesHistory(t *testing.T) {
+	pen := primitive.NewObjectID()
+	shirt := primitive.NewObjectID()
+	cart := models.Cart{
+		TeacherID: "t1",
+		StudentID: "s1",
+		Items: []models.CartItem{
+			{ProductID: pen, Quantity: 3},
+			{ProductID: shirt, Variation: &models.VariationSelection{VariationName: "Size", Option: "M"}, Quantity: 1},
+		},
+	}
+	actor := &models.Actor{UserID: "t1", RequestID: "req-1"}
+	ctx := models.ContextWithActor(context.Background(), actor)
+
+	for _, eventType := range []string{"clear", "order"} {
+		t.Run(eventType, func(t *testing.T) {
+			records := cartLinesHistory(ctx, cart, eventType)
+			if len(records) != len(cart.Items) {
+				t.Fatalf("cartLinesHistory() returned %d records, want %d", len(records), len(cart.Items))
+			}
+
+			for i, record := range records {
+				history := record.(models.CartHistory)
+				item := cart.Items[i]
+				if history.EventType != eventType || history.TeacherID != "t1" || history.StudentID != "s1" ||
+					history.ProductID != item.ProductID || history.Quantity != item.Quantity || !history.Variation.Equal(item.Variation) {
+					t.Errorf("record %d = %+v, want a %q event for %+v", i, history, eventType, item)
+				}
+				if history.Actor != actor {
+					t.Errorf("record %d actor = %+v, want %+v", i, history.Actor, actor)
+				}
+			}
+		})
+	}
+
+	if records := cartLinesHistory(ctx, models.Cart{TeacherID: "t1"}, "clear"); len(records) != 0 {
+		t.Errorf("empty cart produced %d records", len(records))
+	}
+}
+
+func TestOrderedQuantityPipelineCountsOrdersOnly(t *testing.T) {
+	productID := primitive.NewObjectID()
+
+	tests := []struct {
+		name      string
+		studentID string
+		want      bson.M
+	}{
+		{
+			name: "teacher",
+			want: bson.M{"teacher_id": "t1", "product_id": productID, "event_type": "order"},
+		},
+		{
+			name:      "student",
+			studentID: "s1",
+			want:      bson.M{"teacher_id": "t1", "product_id": productID, "event_type": "order", "student_id": "s1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := orderedQuantityPipeline("t1", tt.studentID, productID)
+
+			match := pipeline[0].(bson.M)["$match"].(bson.M)
+			if len(match) != len(tt.want) {
+				t.Fatalf("$match = %v, want %v", match, tt.want)
+			}
+			for key, value := range tt.want {
+				if match[key] != value {
+					t.Errorf("$match[%q] = %v, want %v", key, match[key], value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"store/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ShareLinkRepository interface {
+	CreateShareLink(ctx context.Context, link *models.ShareLink) error
+	GetShareLink(ctx context.Context, linkID primitive.ObjectID) (*models.ShareLink, error)
+	GetShareLinks(ctx context.Context, teacherID string, studentID string) ([]models.ShareLink, error)
+	RevokeShareLink(ctx context.Context, link *models.ShareLink) error
+}
+
+type shareLinkRepository struct {
+	collection *mongo.Collection
+}
+
+func NewShareLinkRepository(collection *mongo.Collection) ShareLinkRepository {
+	return &shareLinkRepository{
+		collection: collection,
+	}
+}
+
+func (r *shareLinkRepository) CreateShareLink(ctx context.Context, link *models.ShareLink) error {
+
+	link.ID = primitive.NewObjectID()
+	link.CreateAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, link)
+
+	return err
+}
+
+// GetShareLink - Returns the link, or nil when it does not exist.
+func (r *shareLinkRepository) GetShareLink(ctx context.Context, linkID primitive.ObjectID) (*models.ShareLink, error) {
+
+	var link models.ShareLink
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": linkID}).Decode(&link)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &link, nil
+}
+
+// GetShareLinks - Lists the links to the teacher's carts, for one student when studentID is set.
+func (r *shareLinkRepository) GetShareLinks(ctx context.Context, teacherID string, studentID string) ([]models.ShareLink, error) {
+
+	filter := bson.M{"teacher_id": teacherID}
+	if studentID != "" {
+		filter["student_id"] = studentID
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "create_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	links := []models.ShareLink{}
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+func (r *shareLinkRepository) RevokeShareLink(ctx context.Context, link *models.ShareLink) error {
+
+	now := time.Now()
+	link.RevokedAt = &now
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": link.ID}, bson.M{
+		"$set": bson.M{"revoked_at": link.RevokedAt},
+	})
+
+	return err
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"store/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WishlistRepository interface {
+	CreateWishlist(ctx context.Context, wishlist *models.Wishlist) error
+	GetWishlist(ctx context.Context, teacherID string, wishlistID primitive.ObjectID) (*models.Wishlist, error)
+	GetWishlistsByTeacher(ctx context.Context, teacherID string) ([]models.Wishlist, error)
+	DeleteWishlist(ctx context.Context, teacherID string, wishlistID primitive.ObjectID) (bool, error)
+	AddWishlistItem(ctx context.Context, teacherID string, wishlistID primitive.ObjectID, entry models.WishlistEntry) error
+	RemoveWishlistItem(ctx context.Context, teacherID string, wishlistID primitive.ObjectID, productID primitive.ObjectID, variation *models.VariationSelection) error
+}
+
+type wishlistRepository struct {
+	collection *mongo.Collection
+}
+
+func NewWishlistRepository(collection *mongo.Collection) WishlistRepository {
+	return &wishlistRepository{
+		collection: collection,
+	}
+}
+
+func (r *wishlistRepository) CreateWishlist(ctx context.Context, wishlist *models.Wishlist) error {
+
+	wishlist.ID = primitive.NewObjectID()
+	wishlist.Items = []models.WishlistEntry{}
+	wishlist.CreateAt = time.Now()
+	wishlist.UpdateAt = wishlist.CreateAt
+
+	_, err := r.collection.InsertOne(ctx, wishlist)
+
+	return err
+}
+
+// GetWishlist - Returns the teacher's wishlist, or nil when it does not exist or belongs to someone else.
+func (r *wishlistRepository) GetWishlist(ctx context.Context, teacherID string, wishlistID primitive.ObjectID) (*models.Wishlist, error) {
+
+	var wishlist models.Wishlist
+
+	err := r.collection.FindOne(ctx, bson.M{"_id": wishlistID, "teacher_id": teacherID}).Decode(&wishlist)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &wishlist, nil
+}
+
+func (r *wishlistRepository) GetWishlistsByTeacher(ctx context.Context, teacherID string) ([]models.Wishlist, error) {
+
+	opts := options.Find().SetSort(bson.D{{Key: "create_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"teacher_id": teacherID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	wishlists := []models.Wishlist{}
+	if err := cursor.All(ctx, &wishlists); err != nil {
+		return nil, err
+	}
+
+	return wishlists, nil
+}
+
+func (r *wishlistRepository) DeleteWishlist(ctx context.Context, teacherID string, wishlistID primitive.ObjectID) (bool, error) {
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": wishlistID, "teacher_id": teacherID})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount > 0, nil
+}
+
+// AddWishlistItem - Adds the entry, or refreshes the snapshot of an existing entry for the same product and variation.
+func (r *wishlistRepository) AddWishlistItem(ctx context.Context, teacherID string, wishlistID primitive.ObjectID, entry models.WishlistEntry) error {
+
+	wishlist, err := r.GetWishlist(ctx, teacherID, wishlistID)
+	if err != nil {
+		return err
+	}
+	if wishlist == nil {
+		return fmt.Errorf("wishlist not found")
+	}
+
+	found := false
+
+	for i, existing := range wishlist.Items {
+		if existing.SameLine(entry.ProductID, entry.Variation) {
+			entry.AddedAt = existing.AddedAt
+			wishlist.Items[i] = entry
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		wishlist.Items = append(wishlist.Items, entry)
+	}
+
+	return r.updateItems(ctx, wishlist)
+}
+
+func (r *wishlistRepository) RemoveWishlistItem(ctx context.Context, teacherID string, wishlistID primitive.ObjectID, productID primitive.ObjectID, variation *models.VariationSelection) error {
+
+	wishlist, err := r.GetWishlist(ctx, teacherID, wishlistID)
+	if err != nil {
+		return err
+	}
+	if wishlist == nil {
+		return fmt.Errorf("wishlist not found")
+	}
+
+	found := false
+
+	for i, entry := range wishlist.Items {
+		if entry.SameLine(productID, variation) {
+			wishlist.Items = append(wishlist.Items[:i], wishlist.Items[i+1:]...)
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("product not found in wishlist")
+	}
+
+	return r.updateItems(ctx, wishlist)
+}
+
+func (r *wishlistRepository) updateItems(ctx context.Context, wishlist *models.Wishlist) error {
+
+	wishlist.UpdateAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"items":     wishlist.Items,
+			"update_at": wishlist.UpdateAt,
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": wishlist.ID}, update)
+
+	return err
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"store/internal/models"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// approvalReasons - The approval rules the cart matches, or nil when it can be ordered without
+// approval. With the workflow enabled but no rule set, every cart with items needs approval.
+func (s *cartService) approvalReasons(cart *models.Cart) []string {
+	rules := s.cartConfig.Approval
+	if !rules.Enabled || len(cart.Items) == 0 {
+		return nil
+	}
+
+	if rules.StoreTotalThreshold <= 0 && rules.ServiceTotalThreshold <= 0 && len(rules.Categories) == 0 {
+		return []string{"all carts need approval"}
+	}
+
+	cart.CalculateTotals()
+
+	var reasons []string
+
+	if rules.StoreTotalThreshold > 0 && cart.TotalPriceStore > rules.StoreTotalThreshold {
+		reasons = append(reasons, fmt.Sprintf("store total %.2f exceeds %.2f", cart.TotalPriceStore, rules.StoreTotalThreshold))
+	}
+
+	if rules.ServiceTotalThreshold > 0 && cart.TotalPriceService > rules.ServiceTotalThreshold {
+		reasons = append(reasons, fmt.Sprintf("service total %.2f exceeds %.2f", cart.TotalPriceService, rules.ServiceTotalThreshold))
+	}
+
+	matched := map[string]bool{}
+	for _, item := range cart.Items {
+		for _, category := range rules.Categories {
+			if strings.EqualFold(item.CategoryName, category) && !matched[category] {
+				matched[category] = true
+				reasons = append(reasons, fmt.Sprintf("category %q needs approval", category))
+			}
+		}
+	}
+
+	return reasons
+}
+
+// ensureCartsApproved - Refuses to order carts that match an approval rule without being approved.
+func (s *cartService) ensureCartsApproved(carts []models.Cart) error {
+
+	var pending []models.ApprovalDetail
+
+	for i := range carts {
+		cart := &carts[i]
+
+		reasons := s.approvalReasons(cart)
+		if len(reasons) == 0 || cart.CurrentStatus() == models.CartStatusApproved {
+			continue
+		}
+
+		pending = append(pending, models.ApprovalDetail{
+			CartID:    cart.ID.Hex(),
+			StudentID: cart.StudentID,
+			Status:    cart.CurrentStatus(),
+			Reasons:   reasons,
+		})
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	return models.NewServiceError(http.StatusConflict, models.ErrApprovalRequired, fmt.Sprintf("%d cart(s) need approval before checkout", len(pending)), pending)
+}
+
+// cartChanged - Follows up a change to a cart's lines: a cart that was submitted or decided on
+// goes back to draft, since the approval no longer covers what it holds, and the usage and budget
+// warnings are refreshed.
+func (s *cartService) cartChanged(ctx context.Context, cart *models.Cart) error {
+	if err := s.reopenCart(ctx, cart); err != nil {
+		return err
+	}
+	if err := s.refreshUsageWarning(ctx, cart); err != nil {
+		return err
+	}
+	return s.refreshBudgetWarning(ctx, cart)
+}
+
+// reopenCart - Returns a cart to draft. Leaving submitted, approved or rejected is recorded as a
+// "reopened" event; an ordered cart becomes a draft again silently.
+func (s *cartService) reopenCart(ctx context.Context, cart *models.Cart) error {
+
+	status := cart.CurrentStatus()
+	if status == models.CartStatusDraft {
+		return nil
+	}
+
+	cart.Status = models.CartStatusDraft
+	cart.Approval = nil
+
+	if err := s.repoCart.UpdateCartStatus(ctx, cart); err != nil {
+		return fmt.Errorf("failed to update cart status: %w", err)
+	}
+
+	if status == models.CartStatusOrdered {
+		return nil
+	}
+
+	if err := s.repoHistory.AddCartEvent(ctx, *cart, "reopened", ""); err != nil {
+		return fmt.Errorf("unable to add cart history: %w", err)
+	}
+
+	return nil
+}
+
+// markOrdered - Marks the carts that were just ordered.
+func (s *cartService) markOrdered(ctx context.Context, carts []models.Cart) error {
+
+	for i := range carts {
+		cart := &carts[i]
+		if len(cart.Items) == 0 {
+			continue
+		}
+
+		cart.Status = models.CartStatusOrdered
+
+		if err := s.repoCart.UpdateCartStatus(ctx, cart); err != nil {
+			return err
+		}
+
+		if err := s.repoHistory.AddCartEvent(ctx, *cart, "ordered", ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SubmitCart - Submits a cart for approval. It takes edit access to the cart, and the cart must
+// match an approval rule.
+func (s *cartService) SubmitCart(ctx context.Context, req *models.SubmitCartRequest) (*models.Cart, error) {
+
+	if !s.cartConfig.Approval.Enabled {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidOperation, "the approval workflow is not enabled", nil)
+	}
+
+	teacherID, err := s.studentCartOwner(ctx, req.TeacherID, req.OwnerID, req.StudentID, models.CartPermissionEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := s.resolveCart(ctx, teacherID, req.StudentID, req.CartID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cart.CurrentStatus() {
+	case models.CartStatusSubmitted, models.CartStatusApproved:
+		return nil, models.NewServiceError(http.StatusConflict, models.ErrCartStatus, fmt.Sprintf("cart is already %s", cart.CurrentStatus()), nil)
+	}
+
+	if len(cart.Items) == 0 {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidOperation, "cart is empty", nil)
+	}
+
+	reasons := s.approvalReasons(cart)
+	if len(reasons) == 0 {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidOperation, "cart does not need approval", nil)
+	}
+
+	cart.Status = models.CartStatusSubmitted
+	cart.Approval = &models.CartApproval{
+		Reasons:     reasons,
+		SubmittedBy: req.TeacherID,
+		SubmittedAt: time.Now(),
+	}
+
+	if err := s.repoCart.UpdateCartStatus(ctx, cart); err != nil {
+		return nil, fmt.Errorf("failed to update cart status: %w", err)
+	}
+
+	if err := s.repoHistory.AddCartEvent(ctx, *cart, "submitted", ""); err != nil {
+		return nil, fmt.Errorf("unable to add cart history: %w", err)
+	}
+
+	return cart, nil
+}
+
+// GetCartsForApproval - Lists the carts of all teachers in the status, submitted ones by default.
+func (s *cartService) GetCartsForApproval(ctx context.Context, status string) ([]models.Cart, error) {
+
+	if status == "" {
+		status = models.CartStatusSubmitted
+	}
+
+	if !models.ValidCartStatus(status) {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidRequest, fmt.Sprintf("unknown cart status %q", status), nil)
+	}
+
+	return s.repoCart.GetCartsByStatus(ctx, status)
+}
+
+func (s *cartService) ApproveCart(ctx context.Context, cartID string, req *models.CartDecisionRequest) (*models.Cart, error) {
+	return s.decideCart(ctx, cartID, req, models.CartStatusApproved)
+}
+
+func (s *cartService) RejectCart(ctx context.Context, cartID string, req *models.CartDecisionRequest) (*models.Cart, error) {
+
+	if strings.TrimSpace(req.Comment) == "" {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidRequest, "a comment is required to reject a cart", nil)
+	}
+
+	return s.decideCart(ctx, cartID, req, models.CartStatusRejected)
+}
+
+// decideCart - Approves or rejects a submitted cart. Approvers cannot decide on carts they submitted
+// or that belong to them.
+func (s *cartService) decideCart(ctx context.Context, cartID string, req *models.CartDecisionRequest, status string) (*models.Cart, error) {
+
+	id, err := primitive.ObjectIDFromHex(cartID)
+	if err != nil {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidRequest, "invalid cart ID", nil)
+	}
+
+	cart, err := s.repoCart.FindCart(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	if cart == nil {
+		return nil, models.NewServiceError(http.StatusNotFound, models.ErrCartNotFound, "cart not found", nil)
+	}
+
+	if cart.CurrentStatus() != models.CartStatusSubmitted || cart.Approval == nil {
+		return nil, models.NewServiceError(http.StatusConflict, models.ErrCartStatus, fmt.Sprintf("only submitted carts can be decided on; cart is %s", cart.CurrentStatus()), nil)
+	}
+
+	if req.ApproverID == cart.TeacherID || req.ApproverID == cart.Approval.SubmittedBy {
+		return nil, models.NewServiceError(http.StatusForbidden, models.ErrForbidden, "approvers cannot decide on their own carts", nil)
+	}
+
+	now := time.Now()
+	cart.Status = status
+	cart.Approval.DecidedBy = req.ApproverID
+	cart.Approval.DecidedAt = &now
+	cart.Approval.Comment = strings.TrimSpace(req.Comment)
+
+	if err := s.repoCart.UpdateCartStatus(ctx, cart); err != nil {
+		return nil, fmt.Errorf("failed to update cart status: %w", err)
+	}
+
+	if err := s.repoHistory.AddCartEvent(ctx, *cart, status, cart.Approval.Comment); err != nil {
+		return nil, fmt.Errorf("unable to add cart history: %w", err)
+	}
+
+	return cart, nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"store/config"
+	"store/internal/models"
+	"testing"
+)
+
+func TestApprovalReasons(t *testing.T) {
+	items := []models.CartItem{
+		{CategoryName: "Science", Quantity: 2, PriceStore: 40, PriceService: 5},
+		{CategoryName: "Art", Quantity: 1, PriceStore: 30, PriceService: 20},
+	}
+
+	tests := []struct {
+		name  string
+		rules config.ApprovalConfig
+		items []models.CartItem
+		want  []string
+	}{
+		{
+			name:  "workflow disabled",
+			rules: config.ApprovalConfig{StoreTotalThreshold: 10},
+			items: items,
+		},
+		{
+			name:  "empty cart",
+			rules: config.ApprovalConfig{Enabled: true},
+		},
+		{
+			name:  "no rules means every cart",
+			rules: config.ApprovalConfig{Enabled: true},
+			items: items,
+			want:  []string{"all carts need approval"},
+		},
+		{
+			name:  "under the thresholds",
+			rules: config.ApprovalConfig{Enabled: true, StoreTotalThreshold: 110, ServiceTotalThreshold: 30},
+			items: items,
+		},
+		{
+			name:  "store total over the threshold",
+			rules: config.ApprovalConfig{Enabled: true, StoreTotalThreshold: 100},
+			items: items,
+			want:  []string{"store total 110.00 exceeds 100.00"},
+		},
+		{
+			name:  "service total over the threshold",
+			rules: config.ApprovalConfig{Enabled: true, ServiceTotalThreshold: 25},
+			items: items,
+			want:  []string{"service total 30.00 exceeds 25.00"},
+		},
+		{
+			name:  "category matches regardless of case",
+			rules: config.ApprovalConfig{Enabled: true, Categories: []string{"science", "Music"}},
+			items: items,
+			want:  []string{`category "science" needs approval`},
+		},
+		{
+			name:  "category is listed once",
+			rules: config.ApprovalConfig{Enabled: true, Categories: []string{"Art"}},
+			items: append([]models.CartItem{{CategoryName: "Art", Quantity: 1}}, items...),
+			want:  []string{`category "Art" needs approval`},
+		},
+		{
+			name:  "several rules",
+			rules: config.ApprovalConfig{Enabled: true, StoreTotalThreshold: 100, Categories: []string{"Art"}},
+			items: items,
+			want:  []string{"store total 110.00 exceeds 100.00", `category "Art" needs approval`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &cartService{cartConfig: config.CartConfig{Approval: tt.rules}}
+			cart := &models.Cart{Items: append([]models.CartItem{}, tt.items...)}
+
+			if got := s.approvalReasons(cart); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("approvalReasons() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsureCartsApproved(t *testing.T) {
+	s := &cartService{cartConfig: config.CartConfig{Approval: config.ApprovalConfig{Enabled: true, StoreTotalThreshold: 50}}}
+
+	small := []models.CartItem{{Quantity: 1, PriceStore: 20}}
+	large := []models.CartItem{{Quantity: 3, PriceStore: 20}}
+
+	tests := []struct {
+		name        string
+		carts       []models.Cart
+		wantPending []string
+	}{
+		{
+			name:  "no cart matches a rule",
+			carts: []models.Cart{{StudentID: "s1", Items: small}},
+		},
+		{
+			name:  "matching cart is approved",
+			carts: []models.Cart{{StudentID: "s1", Items: large, Status: models.CartStatusApproved}},
+		},
+		{
+			name: "matching carts that are not approved",
+			carts: []models.Cart{
+				{StudentID: "s1", Items: large},
+				{StudentID: "s2", Items: large, Status: models.CartStatusSubmitted},
+				{StudentID: "s3", Items: large, Status: models.CartStatusRejected},
+				{StudentID: "s4", Items: small},
+			},
+			wantPending: []string{"s1", "s2", "s3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ensureCartsApproved(tt.carts)
+			if tt.wantPending == nil {
+				if err != nil {
+					t.Fatalf("ensureCartsApproved: %v", err)
+				}
+				return
+			}
+
+			var serviceErr *models.ServiceError
+			if !errors.As(err, &serviceErr) || serviceErr.ErrorCode != models.ErrApprovalRequired {
+				t.Fatalf("ensureCartsApproved() error = %v, want %s", err, models.ErrApprovalRequired)
+			}
+
+			var pending []string
+			for _, detail := range serviceErr.Details.([]models.ApprovalDetail) {
+				pending = append(pending, detail.StudentID)
+			}
+			if !reflect.DeepEqual(pending, tt.wantPending) {
+				t.Errorf("pending students = %v, want %v", pending, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestDecideCartRefusesConcurrentChange(t *testing.T) {
+	submitted := models.Cart{
+		TeacherID: "t1",
+		StudentID: "s1",
+		Items:     []models.CartItem{{Quantity: 3, PriceStore: 20}},
+		Status:    models.CartStatusSubmitted,
+		Approval:  &models.CartApproval{Reasons: []string{"store total 60.00 exceeds 50.00"}, SubmittedBy: "t1"},
+	}
+
+	tests := []struct {
+		name       string
+		concurrent func(cart models.Cart) models.Cart
+	}{
+		{
+			name: "another supervisor decided first",
+			concurrent: func(cart models.Cart) models.Cart {
+				cart.Status = models.CartStatusRejected
+				cart.Approval.DecidedBy = "sup-2"
+				cart.Approval.Comment = "too expensive"
+				return cart
+			},
+		},
+		{
+			name: "cart edited and resubmitted",
+			concurrent: func(cart models.Cart) models.Cart {
+				cart.Items = append(cart.Items, models.CartItem{Quantity: 10, PriceStore: 20})
+				cart.Approval.Reasons = []string{"store total 260.00 exceeds 50.00"}
+				return cart
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeCartRepository()
+			cart := repo.put(cloneCart(submitted))
+
+			// The concurrent change lands between this decision's read and its write.
+			var changed models.Cart
+			repo.afterFind = func() {
+				repo.afterFind = nil
+				changed = repo.put(tt.concurrent(repo.get(cart.ID)))
+			}
+
+			s := &cartService{repoCart: repo}
+			_, err := s.ApproveCart(context.Background(), cart.ID.Hex(), &models.CartDecisionRequest{ApproverID: "sup-1"})
+
+			var serviceErr *models.ServiceError
+			if !errors.As(err, &serviceErr) || serviceErr.StatusCode != http.StatusConflict || serviceErr.ErrorCode != models.ErrCartModified {
+				t.Fatalf("ApproveCart() error = %v, want %s", err, models.ErrCartModified)
+			}
+
+			if stored := repo.get(cart.ID); !reflect.DeepEqual(stored, changed) {
+				t.Errorf("stored cart = %+v, want the concurrent change %+v kept", stored, changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"store/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// activeBudgets - The budgets of the teacher and of the students whose term is running.
+func (s *cartService) activeBudgets(ctx context.Context, teacherID string, studentIDs []string) ([]models.Budget, error) {
+
+	now := time.Now()
+
+	budgets, err := s.budgets.GetActiveBudgets(ctx, models.BudgetScopeTeacher, []string{teacherID}, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budgets: %w", err)
+	}
+
+	students, err := s.budgets.GetActiveBudgets(ctx, models.BudgetScopeStudent, studentIDs, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budgets: %w", err)
+	}
+
+	return append(budgets, students...), nil
+}
+
+// budgetStatuses - Works out each budget against what was ordered during its term and what the carts
+// hold. A student budget only counts that student's carts.
+func (s *cartService) budgetStatuses(ctx context.Context, budgets []models.Budget, carts []models.Cart) ([]models.BudgetStatus, error) {
+
+	statuses := make([]models.BudgetStatus, 0, len(budgets))
+
+	for _, budget := range budgets {
+		teacherID, studentID := budget.ScopeID, ""
+		if budget.Scope == models.BudgetScopeStudent {
+			teacherID, studentID = "", budget.ScopeID
+		}
+
+		spentStore, spentService, err := s.repoHistory.SumOrderedSpend(ctx, teacherID, studentID, budget.StartsAt, budget.EndsAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ordered spending: %w", err)
+		}
+
+		var inCartStore, inCartService float64
+		for _, cart := range carts {
+			if studentID != "" && cart.StudentID != studentID {
+				continue
+			}
+			inCartStore += cart.TotalPriceStore
+			inCartService += cart.TotalPriceService
+		}
+
+		statuses = append(statuses, models.NewBudgetStatus(budget, spentStore, spentService, inCartStore, inCartService))
+	}
+
+	return statuses, nil
+}
+
+// budgetError - Refuses when the carts go past a budget in reject mode.
+func budgetError(statuses []models.BudgetStatus) error {
+
+	var exceeded []models.BudgetStatus
+	for _, status := range statuses {
+		if status.Mode == models.BudgetModeReject && status.Exceeded() {
+			exceeded = append(exceeded, status)
+		}
+	}
+
+	if len(exceeded) == 0 {
+		return nil
+	}
+
+	first := exceeded[0]
+	return models.NewServiceError(http.StatusConflict, models.ErrBudgetExceeded, fmt.Sprintf("spending would exceed the %s budget of %s for term %q", first.Scope, first.ScopeID, first.Term), exceeded)
+}
+
+// ensureWithinBudgets - Refuses to add requested units of the line when that takes the cart's
+// checkout group past a budget in reject mode.
+func (s *cartService) ensureWithinBudgets(ctx context.Context, cart *models.Cart, item *models.CartItem, requested int) error {
+
+	carts, err := s.checkoutGroup(ctx, cart)
+	if err != nil {
+		return err
+	}
+
+	return s.checkBudgets(ctx, cart, carts, item, requested)
+}
+
+// checkBudgets - The check behind ensureWithinBudgets, against an already loaded checkout group. Like
+// ensureCartsWithinBudgets at checkout, it applies the budgets of every student in the group.
+func (s *cartService) checkBudgets(ctx context.Context, cart *models.Cart, carts []models.Cart, item *models.CartItem, requested int) error {
+
+	line := *item
+	line.Quantity = requested
+
+	projected := *cart
+	projected.Items = models.MergeCartLine(append([]models.CartItem{}, cart.Items...), line)
+	projected.CalculateTotals()
+
+	group := make([]models.Cart, 0, len(carts)+1)
+	found := false
+	for _, grouped := range carts {
+		if grouped.ID == cart.ID {
+			grouped = projected
+			found = true
+		}
+		group = append(group, grouped)
+	}
+	if !found {
+		group = append(group, projected)
+	}
+
+	budgets, err := s.activeBudgets(ctx, cart.TeacherID, cartStudentIDs(group))
+	if err != nil || len(budgets) == 0 {
+		return err
+	}
+
+	statuses, err := s.budgetStatuses(ctx, budgets, group)
+	if err != nil {
+		return err
+	}
+
+	return budgetError(statuses)
+}
+
+// ensureCartsWithinBudgets - Refuses to order carts that go past a budget in reject mode.
+func (s *cartService) ensureCartsWithinBudgets(ctx context.Context, teacherID string, carts []models.Cart) error {
+
+	budgets, err := s.activeBudgets(ctx, teacherID, cartStudentIDs(carts))
+	if err != nil || len(budgets) == 0 {
+		return err
+	}
+
+	statuses, err := s.budgetStatuses(ctx, budgets, carts)
+	if err != nil {
+		return err
+	}
+
+	return budgetError(statuses)
+}
+
+// refreshBudgetWarning - Flags a cart whose checkout group goes past a budget in warn mode after a
+// change, and clears the flag once it is back within all of them.
+func (s *cartService) refreshBudgetWarning(ctx context.Context, cart *models.Cart) error {
+
+	budgets, err := s.activeBudgets(ctx, cart.TeacherID, []string{cart.StudentID})
+	if err != nil {
+		return err
+	}
+
+	warned := false
+	for _, warning := range cart.Warnings {
+		warned = warned || warning.Code == models.WarningBudget
+	}
+
+	if len(budgets) == 0 && !warned {
+		return nil
+	}
+
+	carts, err := s.checkoutGroup(ctx, cart)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := s.budgetStatuses(ctx, budgets, carts)
+	if err != nil {
+		return err
+	}
+
+	cart.ClearWarning(models.WarningBudget)
+	for _, status := range statuses {
+		if status.Mode == models.BudgetModeWarn && status.Exceeded() {
+			cart.SetWarning(models.WarningBudget, fmt.Sprintf("cart goes past the %s budget for term %q", status.Scope, status.Term))
+			break
+		}
+	}
+
+	return s.repoCart.UpdateCart(ctx, cart)
+}
+
+// withBudgets - Adds the budgets that apply to each of the teacher's default carts, as returned by
+// GetCartByTeacher. The default carts are ordered together, so a teacher budget counts all of them.
+func (s *cartService) withBudgets(ctx context.Context, teacherID string, carts []bson.M) error {
+
+	defaults, err := s.repoCart.GetCartsByTeacher(ctx, teacherID)
+	if err != nil {
+		return fmt.Errorf("failed to get carts: %w", err)
+	}
+
+	budgets, err := s.activeBudgets(ctx, teacherID, cartStudentIDs(defaults))
+	if err != nil || len(budgets) == 0 {
+		return err
+	}
+
+	statuses, err := s.budgetStatuses(ctx, budgets, defaults)
+	if err != nil {
+		return err
+	}
+
+	for _, cart := range carts {
+		studentID, _ := cart["_id"].(string)
+		cart["budgets"] = budgetsFor(statuses, studentID)
+	}
+
+	return nil
+}
+
+// withNamedCartBudgets - Adds the budgets that apply to each named cart, which is ordered on its own.
+func (s *cartService) withNamedCartBudgets(ctx context.Context, teacherID string, carts []models.Cart) error {
+
+	budgets, err := s.activeBudgets(ctx, teacherID, cartStudentIDs(carts))
+	if err != nil || len(budgets) == 0 {
+		return err
+	}
+
+	for i := range carts {
+		var applicable []models.Budget
+		for _, budget := range budgets {
+			if budget.Scope == models.BudgetScopeTeacher || budget.ScopeID == carts[i].StudentID {
+				applicable = append(applicable, budget)
+			}
+		}
+
+		statuses, err := s.budgetStatuses(ctx, applicable, []models.Cart{carts[i]})
+		if err != nil {
+			return err
+		}
+		carts[i].Budgets = statuses
+	}
+
+	return nil
+}
+
+// budgetsFor - The teacher budgets and the student's own budgets.
+func budgetsFor(statuses []models.BudgetStatus, studentID string) []models.BudgetStatus {
+	applicable := []models.BudgetStatus{}
+	for _, status := range statuses {
+		if status.Scope == models.BudgetScopeTeacher || status.ScopeID == studentID {
+			applicable = append(applicable, status)
+		}
+	}
+	return applicable
+}
+
+// cartStudentIDs - The students the carts belong to, each once.
+func cartStudentIDs(carts []models.Cart) []string {
+	seen := map[string]bool{}
+	var studentIDs []string
+	for _, cart := range carts {
+		if !seen[cart.StudentID] {
+			seen[cart.StudentID] = true
+			studentIDs = append(studentIDs, cart.StudentID)
+		}
+	}
+	return studentIDs
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"store/internal/models"
+	"store/internal/repository"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BudgetService interface {
+	GetBudgets(ctx context.Context, scope string, scopeID string) ([]models.Budget, error)
+	CreateBudget(ctx context.Context, req *models.BudgetRequest) (*models.Budget, error)
+	UpdateBudget(ctx context.Context, budgetID string, req *models.BudgetRequest) (*models.Budget, error)
+	DeleteBudget(ctx context.Context, budgetID string) error
+}
+
+type budgetService struct {
+	repo repository.BudgetRepository
+}
+
+func NewBudgetService(repo repository.BudgetRepository) BudgetService {
+	return &budgetService{
+		repo: repo,
+	}
+}
+
+func (s *budgetService) GetBudgets(ctx context.Context, scope string, scopeID string) ([]models.Budget, error) {
+	return s.repo.GetBudgets(ctx, scope, scopeID)
+}
+
+func (s *budgetService) CreateBudget(ctx context.Context, req *models.BudgetRequest) (*models.Budget, error) {
+
+	budget := &models.Budget{CreatedBy: req.AdminID}
+	if err := s.applyRequest(ctx, budget, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateBudget(ctx, budget); err != nil {
+		return nil, fmt.Errorf("failed to create budget: %w", err)
+	}
+
+	return budget, nil
+}
+
+func (s *budgetService) UpdateBudget(ctx context.Context, budgetID string, req *models.BudgetRequest) (*models.Budget, error) {
+
+	budget, err := s.getBudget(ctx, budgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyRequest(ctx, budget, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateBudget(ctx, budget); err != nil {
+		return nil, fmt.Errorf("failed to update budget: %w", err)
+	}
+
+	return budget, nil
+}
+
+func (s *budgetService) DeleteBudget(ctx context.Context, budgetID string) error {
+
+	id, err := primitive.ObjectIDFromHex(budgetID)
+	if err != nil {
+		return models.NewServiceError(http.StatusBadRequest, models.ErrInvalidRequest, "invalid budget ID", nil)
+	}
+
+	deleted, err := s.repo.DeleteBudget(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+
+	if !deleted {
+		return models.NewServiceError(http.StatusNotFound, models.ErrBudgetNotFound, "budget not found", nil)
+	}
+
+	return nil
+}
+
+func (s *budgetService) getBudget(ctx context.Context, budgetID string) (*models.Budget, error) {
+
+	id, err := primitive.ObjectIDFromHex(budgetID)
+	if err != nil {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidRequest, "invalid budget ID", nil)
+	}
+
+	budget, err := s.repo.GetBudget(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	if budget == nil {
+		return nil, models.NewServiceError(http.StatusNotFound, models.ErrBudgetNotFound, "budget not found", nil)
+	}
+
+	return budget, nil
+}
+
+// applyRequest - Copies the request onto the budget once it is checked: a limit must be set and the
+// term name must be free for the student or teacher.
+func (s *budgetService) applyRequest(ctx context.Context, budget *models.Budget, req *models.BudgetRequest) error {
+
+	if req.LimitStore <= 0 && req.LimitService <= 0 {
+		return models.NewServiceError(http.StatusBadRequest, models.ErrInvalidRequest, "limit_store or limit_service must be set", nil)
+	}
+
+	term := strings.TrimSpace(req.Term)
+
+	existing, err := s.repo.GetBudgets(ctx, req.Scope, req.ScopeID)
+	if err != nil {
+		return fmt.Errorf("failed to get budgets: %w", err)
+	}
+
+	for _, other := range existing {
+		if other.ID != budget.ID && strings.EqualFold(other.Term, term) {
+			return models.NewServiceError(http.StatusConflict, models.ErrBudgetConflict, fmt.Sprintf("a %s budget for term %q already exists", req.Scope, term), nil)
+		}
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = models.BudgetModeReject
+	}
+
+	budget.Scope = req.Scope
+	budget.ScopeID = req.ScopeID
+	budget.Term = term
+	budget.StartsAt = req.StartsAt
+	budget.EndsAt = req.EndsAt
+	budget.LimitStore = req.LimitStore
+	budget.LimitService = req.LimitService
+	budget.Mode = mode
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"store/internal/models"
+	"store/internal/repository"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestBudgetError(t *testing.T) {
+	over := -5.0
+	within := 20.0
+
+	tests := []struct {
+		name     string
+		statuses []models.BudgetStatus
+		wantIDs  []string
+	}{
+		{
+			name:     "within every budget",
+			statuses: []models.BudgetStatus{{BudgetID: "b1", Mode: models.BudgetModeReject, RemainingStore: &within}},
+		},
+		{
+			name:     "warn mode is never refused",
+			statuses: []models.BudgetStatus{{BudgetID: "b1", Mode: models.BudgetModeWarn, RemainingStore: &over}},
+		},
+		{
+			name: "reject mode past the limit",
+			statuses: []models.BudgetStatus{
+				{BudgetID: "b1", Mode: models.BudgetModeReject, RemainingStore: &within},
+				{BudgetID: "b2", Mode: models.BudgetModeReject, Scope: models.BudgetScopeTeacher, RemainingService: &over},
+				{BudgetID: "b3", Mode: models.BudgetModeWarn, RemainingStore: &over},
+				{BudgetID: "b4", Mode: models.BudgetModeReject, Scope: models.BudgetScopeStudent, RemainingStore: &over},
+			},
+			wantIDs: []string{"b2", "b4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := budgetError(tt.statuses)
+			if tt.wantIDs == nil {
+				if err != nil {
+					t.Fatalf("budgetError: %v", err)
+				}
+				return
+			}
+
+			var serviceErr *models.ServiceError
+			if !errors.As(err, &serviceErr) || serviceErr.ErrorCode != models.ErrBudgetExceeded {
+				t.Fatalf("budgetError() = %v, want %s", err, models.ErrBudgetExceeded)
+			}
+
+			var ids []string
+			for _, status := range serviceErr.Details.([]models.BudgetStatus) {
+				ids = append(ids, status.BudgetID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("exceeded budgets = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestBudgetsFor(t *testing.T) {
+	statuses := []models.BudgetStatus{
+		{BudgetID: "teacher", Scope: models.BudgetScopeTeacher, ScopeID: "t1"},
+		{BudgetID: "s1", Scope: models.BudgetScopeStudent, ScopeID: "s1"},
+		{BudgetID: "s2", Scope: models.BudgetScopeStudent, ScopeID: "s2"},
+	}
+
+	tests := []struct {
+		studentID string
+		want      []string
+	}{
+		{studentID: "s1", want: []string{"teacher", "s1"}},
+		{studentID: "s3", want: []string{"teacher"}},
+	}
+
+	for _, tt := range tests {
+		var ids []string
+		for _, status := range budgetsFor(statuses, tt.studentID) {
+			ids = append(ids, status.BudgetID)
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("budgetsFor(%q) = %v, want %v", tt.studentID, ids, tt.want)
+		}
+	}
+}
+
+func TestCartStudentIDs(t *testing.T) {
+	carts := []models.Cart{{StudentID: "s1"}, {StudentID: "s2"}, {StudentID: "s1", Named: true}}
+
+	if got, want := cartStudentIDs(carts), []string{"s1", "s2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cartStudentIDs() = %v, want %v", got, want)
+	}
+}
+
+func TestEnsureWithinBudgetsCountsCheckoutGroup(t *testing.T) {
+	pen := models.CartItem{ProductID: primitive.NewObjectID(), Quantity: 3, PriceStore: 2}
+	book := models.CartItem{ProductID: primitive.NewObjectID(), Quantity: 1, PriceStore: 3}
+
+	budget := func(scope string, scopeID string, limit float64) models.Budget {
+		return models.Budget{ID: primitive.NewObjectID(), Scope: scope, ScopeID: scopeID, Term: "spring", LimitStore: limit, Mode: models.BudgetModeReject}
+	}
+
+	tests := []struct {
+		name    string
+		budget  models.Budget
+		wantErr bool
+	}{
+		{name: "teacher budget counts every default cart", budget: budget(models.BudgetScopeTeacher, "t1", 10), wantErr: true},
+		{name: "teacher budget with room for the line", budget: budget(models.BudgetScopeTeacher, "t1", 11)},
+		{name: "student budget counts the student's cart only", budget: budget(models.BudgetScopeStudent, "s1", 8)},
+		{name: "student budget of another student in the group", budget: budget(models.BudgetScopeStudent, "s2", 2), wantErr: true},
+		{name: "budget of a student outside the group", budget: budget(models.BudgetScopeStudent, "s3", 1)},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			// No past orders, for the add and then for the checkout check.
+			mt.AddMockResponses(
+				mtest.CreateCursorResponse(0, "store.cart_history", mtest.FirstBatch),
+				mtest.CreateCursorResponse(0, "store.cart_history", mtest.FirstBatch),
+			)
+
+			first := models.Cart{TeacherID: "t1", StudentID: "s1", Items: []models.CartItem{pen}}
+			second := models.Cart{TeacherID: "t1", StudentID: "s2", Items: []models.CartItem{book}}
+			first.CalculateTotals()
+			second.CalculateTotals()
+
+			repo := newFakeCartRepository()
+			first, second = repo.put(first), repo.put(second)
+
+			s := &cartService{
+				repoCart:    repo,
+				repoHistory: *repository.NewCartHistoryRepository(mt.Coll, mt.Coll),
+				budgets:     &fakeBudgetRepository{budgets: []models.Budget{tt.budget}},
+			}
+
+			line := models.CartItem{ProductID: pen.ProductID, Quantity: 1, PriceStore: 2}
+			err := s.ensureWithinBudgets(context.Background(), &first, &line, 1)
+			if (err != nil) != tt.wantErr {
+				mt.Fatalf("ensureWithinBudgets() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			// Checkout refuses the same carts.
+			first.Items = models.MergeCartLine(first.Items, line)
+			first.CalculateTotals()
+			checkoutErr := s.ensureCartsWithinBudgets(context.Background(), "t1", []models.Cart{first, second})
+			if (checkoutErr != nil) != tt.wantErr {
+				mt.Errorf("ensureCartsWithinBudgets() error = %v, want error %v", checkoutErr, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"store/internal/models"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	bulkStatusAdded      = "added"
+	bulkStatusFailed     = "failed"
+	bulkStatusNotApplied = "not_applied"
+)
+
+// bulkAdd - State shared by the lines of one bulk add. Products, teacher checks and carts are looked
+// up once each, and carts are changed in memory until the request is applied. originals keeps every
+// pending cart as it was read, to put back if an atomic request cannot be saved in full.
+type bulkAdd struct {
+	products    map[primitive.ObjectID]*productSnapshot
+	productErrs map[primitive.ObjectID]error
+	students    map[string]bulkStudent
+	carts       map[string]*models.Cart
+	pending     []*models.Cart
+	originals   map[primitive.ObjectID]models.Cart
+	added       []bulkAddedLine
+}
+
+// bulkStudent - The outcome of checking the caller's access to a student's carts.
+type bulkStudent struct {
+	teacherID string
+	err       error
+}
+
+// bulkAddedLine - A line that passed its checks, with the cart it was added to.
+type bulkAddedLine struct {
+	index int
+	cart  *models.Cart
+	item  models.CartItem
+}
+
+func newBulkAdd() *bulkAdd {
+	return &bulkAdd{
+		products:    map[primitive.ObjectID]*productSnapshot{},
+		productErrs: map[primitive.ObjectID]error{},
+		students:    map[string]bulkStudent{},
+		carts:       map[string]*models.Cart{},
+		originals:   map[primitive.ObjectID]models.Cart{},
+	}
+}
+
+// BulkAddToCart - Adds many lines, each checked like a single add against the carts as the earlier
+// lines left them. In best-effort mode the lines that pass are saved and the rest reported; in
+// atomic mode any failing line, or any cart that cannot be saved, refuses the whole request.
+func (s *cartService) BulkAddToCart(ctx context.Context, req *models.BulkAddToCartRequest) (*models.BulkAddResult, error) {
+
+	state := newBulkAdd()
+	result := &models.BulkAddResult{
+		Atomic: req.Atomic,
+		Lines:  make([]models.BulkLineResult, 0, len(req.Items)),
+	}
+
+	for i, line := range req.Items {
+		lineResult := models.BulkLineResult{
+			Index:     i,
+			StudentID: line.StudentID,
+			ProductID: line.ProductID,
+		}
+
+		item, err := s.bulkAddLine(ctx, state, req, i, line)
+		if err != nil {
+			lineResult.Status = bulkStatusFailed
+			lineResult.Error, lineResult.ErrorCode, lineResult.Details = describeError(err)
+			result.Failed++
+		} else {
+			lineResult.Status = bulkStatusAdded
+			lineResult.Item = item
+			result.Succeeded++
+		}
+
+		result.Lines = append(result.Lines, lineResult)
+	}
+
+	if req.Atomic && result.Failed > 0 {
+		for _, added := range state.added {
+			result.Lines[added.index].Status = bulkStatusNotApplied
+		}
+		result.Succeeded = 0
+
+		return nil, models.NewServiceError(http.StatusUnprocessableEntity, models.ErrBulkAddFailed, fmt.Sprintf("%d of %d lines failed, nothing was added", result.Failed, len(req.Items)), result)
+	}
+
+	if err := s.applyBulkAdd(ctx, state, result); err != nil {
+		return nil, err
+	}
+
+	result.Applied = result.Succeeded > 0
+
+	return result, nil
+}
+
+// bulkAddLine - Runs the checks of a single add for one line and adds it to its cart in memory.
+func (s *cartService) bulkAddLine(ctx context.Context, state *bulkAdd, req *models.BulkAddToCartRequest, index int, line models.BulkAddLine) (*models.CartItem, error) {
+
+	productID, err := primitive.ObjectIDFromHex(line.ProductID)
+	if err != nil {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidRequest, "invalid product ID format", nil)
+	}
+
+	access, checked := state.students[line.StudentID]
+	if !checked {
+		access.teacherID, access.err = s.studentCartOwner(ctx, req.TeacherID, req.OwnerID, line.StudentID, models.CartPermissionEdit)
+		state.students[line.StudentID] = access
+	}
+	if access.err != nil {
+		return nil, access.err
+	}
+	teacherID := access.teacherID
+
+	cart, err := s.bulkCart(ctx, state, teacherID, line)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.bulkProduct(ctx, state, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := product.cartItem(line.Quantity, line.Variation)
+	if err != nil {
+		return nil, err
+	}
+
+	carts, err := s.pendingGroup(ctx, state, cart)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkPurchaseLimit(ctx, cart, carts, product, line.Quantity); err != nil {
+		return nil, err
+	}
+
+	if err := checkLineStock(cart, carts, product, item, line.Quantity); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureWithinUsageBudget(cart, item, line.Quantity); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkBudgets(ctx, cart, carts, item, line.Quantity); err != nil {
+		return nil, err
+	}
+
+	cart.Items = models.MergeCartLine(cart.Items, *item)
+	state.added = append(state.added, bulkAddedLine{index: index, cart: cart, item: *item})
+
+	return item, nil
+}
+
+// bulkCart - The cart a line goes to, shared with earlier lines that named the same cart.
+func (s *cartService) bulkCart(ctx context.Context, state *bulkAdd, teacherID string, line models.BulkAddLine) (*models.Cart, error) {
+
+	key := line.StudentID + "|" + line.CartID
+	if cart, ok := state.carts[key]; ok {
+		return cart, nil
+	}
+
+	cart, err := s.resolveCart(ctx, teacherID, line.StudentID, line.CartID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The same cart may be reached through another key, e.g. by its ID and as the default cart.
+	for _, pending := range state.pending {
+		if pending.ID == cart.ID {
+			state.carts[key] = pending
+			return pending, nil
+		}
+	}
+
+	original := *cart
+	original.Items = append([]models.CartItem(nil), cart.Items...)
+	state.originals[cart.ID] = original
+
+	state.pending = append(state.pending, cart)
+	state.carts[key] = cart
+	return cart, nil
+}
+
+// bulkProduct - Fetches a product once per request; a failed lookup fails every line that uses it.
+func (s *cartService) bulkProduct(ctx context.Context, state *bulkAdd, productID primitive.ObjectID) (*productSnapshot, error) {
+
+	if err, ok := state.productErrs[productID]; ok {
+		return nil, err
+	}
+
+	if product, ok := state.products[productID]; ok {
+		return product, nil
+	}
+
+	product, err := s.productAPI.GetProduct(ctx, productID)
+	if err != nil {
+		state.productErrs[productID] = err
+		return nil, err
+	}
+
+	state.products[productID] = product
+	return product, nil
+}
+
+// pendingGroup - The checkout group of cart, with the carts changed by earlier lines standing in for
+// their stored copies.
+func (s *cartService) pendingGroup(ctx context.Context, state *bulkAdd, cart *models.Cart) ([]models.Cart, error) {
+
+	carts, err := s.checkoutGroup(ctx, cart)
+	if err != nil || cart.Named {
+		return carts, err
+	}
+
+	for _, pending := range state.pending {
+		if pending.Named {
+			continue
+		}
+
+		replaced := false
+		for i := range carts {
+			if carts[i].ID == pending.ID {
+				carts[i] = *pending
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			carts = append(carts, *pending)
+		}
+	}
+
+	return carts, nil
+}
+
+// applyBulkAdd - Saves the carts that lines were added to and records an "add" event per line of
+// the saved carts. A cart that cannot be saved fails its lines. In best-effort mode the other carts
+// are still saved; in atomic mode the carts saved before it are put back as they were read and the
+// request is refused.
+func (s *cartService) applyBulkAdd(ctx context.Context, state *bulkAdd, result *models.BulkAddResult) error {
+
+	changed := map[primitive.ObjectID]bool{}
+	for _, added := range state.added {
+		changed[added.cart.ID] = true
+	}
+
+	saved := map[primitive.ObjectID]bool{}
+	var savedCarts []*models.Cart
+
+	for _, cart := range state.pending {
+		if !changed[cart.ID] {
+			continue
+		}
+
+		cart.CalculateTotals()
+
+		if err := s.repoCart.UpdateCart(ctx, cart); err != nil {
+			failBulkCart(state, result, cart, err)
+
+			if result.Atomic {
+				return s.refuseBulkAdd(ctx, state, result, savedCarts, cart, err)
+			}
+			continue
+		}
+
+		saved[cart.ID] = true
+		savedCarts = append(savedCarts, cart)
+	}
+
+	for _, added := range state.added {
+		if !saved[added.cart.ID] {
+			continue
+		}
+
+		if err := s.repoHistory.AddCartHistory(ctx, added.cart.TeacherID, added.cart.StudentID, added.item, "add", added.item.Quantity); err != nil {
+			return fmt.Errorf("unable to add cart history: %w", err)
+		}
+	}
+
+	for _, cart := range savedCarts {
+		if err := s.cartChanged(ctx, cart); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// failBulkCart - Reports the lines added to a cart that could not be saved as failed with err.
+func failBulkCart(state *bulkAdd, result *models.BulkAddResult, cart *models.Cart, err error) {
+	for _, added := range state.added {
+		if added.cart != cart {
+			continue
+		}
+
+		line := &result.Lines[added.index]
+		line.Status = bulkStatusFailed
+		line.Item = nil
+		line.Error, line.ErrorCode, line.Details = describeError(err)
+		result.Succeeded--
+		result.Failed++
+	}
+}
+
+// refuseBulkAdd - Undoes an atomic bulk add after failed could not be saved: the carts saved before it
+// are written back as they were read and every other added line is reported as not applied. The
+// refusal carries the status of the save error, and says so when a cart could not be put back.
+func (s *cartService) refuseBulkAdd(ctx context.Context, state *bulkAdd, result *models.BulkAddResult, savedCarts []*models.Cart, failed *models.Cart, err error) error {
+
+	for _, added := range state.added {
+		if result.Lines[added.index].Status == bulkStatusAdded {
+			result.Lines[added.index].Status = bulkStatusNotApplied
+		}
+	}
+	result.Succeeded = 0
+
+	var notRestored []string
+	for _, cart := range savedCarts {
+		original := state.originals[cart.ID]
+		original.UpdateAt = cart.UpdateAt
+
+		if restoreErr := s.repoCart.UpdateCart(ctx, &original); restoreErr != nil {
+			notRestored = append(notRestored, fmt.Sprintf("%s (%v)", cart.ID.Hex(), restoreErr))
+			continue
+		}
+		*cart = original
+	}
+
+	status := http.StatusInternalServerError
+	var serviceErr *models.ServiceError
+	if errors.As(err, &serviceErr) {
+		status = serviceErr.StatusCode
+	}
+
+	if len(notRestored) > 0 {
+		message := fmt.Sprintf("failed to save the cart of student %s, and %d saved carts could not be restored: %s", failed.StudentID, len(notRestored), strings.Join(notRestored, ", "))
+		return models.NewServiceError(http.StatusInternalServerError, models.ErrBulkAddFailed, message, result)
+	}
+
+	return models.NewServiceError(status, models.ErrBulkAddFailed, fmt.Sprintf("failed to save the cart of student %s, nothing was added", failed.StudentID), result)
+}
+
+// describeError - The message, and for a service error the code and details, to report a failure
+// of one part of a multi-part request with.
+func describeError(err error) (message string, code string, details interface{}) {
+	var serviceErr *models.ServiceError
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Message, serviceErr.ErrorCode, serviceErr.Details
+	}
+	return err.Error(), "", nil
+}
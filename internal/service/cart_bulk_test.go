@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"store/internal/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestApplyBulkAddSaveFailure(t *testing.T) {
+	pen := models.CartItem{ProductID: primitive.NewObjectID(), ProductName: "Pen", Quantity: 1, PriceStore: 2}
+	book := models.CartItem{ProductID: primitive.NewObjectID(), ProductName: "Book", Quantity: 1, PriceStore: 10}
+	conflict := models.NewServiceError(http.StatusConflict, models.ErrCartModified, "cart was changed by another request; reload it and try again", nil)
+
+	tests := []struct {
+		name string
+		// failures are the write errors of the first and second cart; nil saves the cart.
+		failures   [2]error
+		atomic     bool
+		wantStatus int
+		wantLines  []string
+		wantStored [2][]models.CartItem
+	}{
+		{
+			name:       "atomic restores the carts saved before the failure",
+			failures:   [2]error{nil, conflict},
+			atomic:     true,
+			wantStatus: http.StatusConflict,
+			wantLines:  []string{bulkStatusNotApplied, bulkStatusNotApplied, bulkStatusFailed},
+			wantStored: [2][]models.CartItem{{pen}, nil},
+		},
+		{
+			name:       "atomic refuses a database error with 500",
+			failures:   [2]error{nil, errors.New("connection reset")},
+			atomic:     true,
+			wantStatus: http.StatusInternalServerError,
+			wantLines:  []string{bulkStatusNotApplied, bulkStatusNotApplied, bulkStatusFailed},
+			wantStored: [2][]models.CartItem{{pen}, nil},
+		},
+		{
+			name:       "best effort reports the lines of every cart that failed",
+			failures:   [2]error{errors.New("connection reset"), conflict},
+			wantLines:  []string{bulkStatusFailed, bulkStatusFailed, bulkStatusFailed},
+			wantStored: [2][]models.CartItem{{pen}, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeCartRepository(
+				models.Cart{TeacherID: "t1", StudentID: "s1", Items: []models.CartItem{pen}},
+				models.Cart{TeacherID: "t1", StudentID: "s2"},
+			)
+			s := &cartService{repoCart: repo}
+
+			state := newBulkAdd()
+			var carts [2]*models.Cart
+			for i, studentID := range []string{"s1", "s2"} {
+				for id, stored := range repo.carts {
+					if stored.StudentID == studentID {
+						cart := cloneCart(stored)
+						carts[i] = &cart
+						state.originals[id] = cloneCart(stored)
+					}
+				}
+				state.pending = append(state.pending, carts[i])
+				if tt.failures[i] != nil {
+					repo.updateErrs[carts[i].ID] = tt.failures[i]
+				}
+			}
+
+			result := &models.BulkAddResult{Atomic: tt.atomic}
+			for i, line := range []struct {
+				cart *models.Cart
+				item models.CartItem
+			}{{carts[0], book}, {carts[0], pen}, {carts[1], book}} {
+				line.cart.Items = models.MergeCartLine(line.cart.Items, line.item)
+				state.added = append(state.added, bulkAddedLine{index: i, cart: line.cart, item: line.item})
+				result.Lines = append(result.Lines, models.BulkLineResult{Index: i, Status: bulkStatusAdded, Item: &line.item})
+				result.Succeeded++
+			}
+
+			err := s.applyBulkAdd(context.Background(), state, result)
+
+			if tt.atomic {
+				var serviceErr *models.ServiceError
+				if !errors.As(err, &serviceErr) || serviceErr.StatusCode != tt.wantStatus || serviceErr.ErrorCode != models.ErrBulkAddFailed {
+					t.Fatalf("applyBulkAdd() error = %v, want a %d %s", err, tt.wantStatus, models.ErrBulkAddFailed)
+				}
+				if serviceErr.Details != result {
+					t.Errorf("refusal details = %v, want the line results", serviceErr.Details)
+				}
+			} else if err != nil {
+				t.Fatalf("applyBulkAdd() error = %v", err)
+			}
+
+			var lines []string
+			for _, line := range result.Lines {
+				lines = append(lines, line.Status)
+				if line.Status == bulkStatusFailed && (line.Item != nil || line.Error == "") {
+					t.Errorf("failed line %d = %+v, want an error and no item", line.Index, line)
+				}
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("line statuses = %v, want %v", lines, tt.wantLines)
+			}
+			if result.Succeeded != 0 {
+				t.Errorf("succeeded = %d, want 0", result.Succeeded)
+			}
+
+			for i, cart := range carts {
+				if stored := repo.get(cart.ID).Items; len(stored) != len(tt.wantStored[i]) || (len(stored) > 0 && !reflect.DeepEqual(stored, tt.wantStored[i])) {
+					t.Errorf("stored cart %d items = %+v, want %+v", i, stored, tt.wantStored[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"store/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	copyModeReplace = "replace"
+
+	copyStatusCopied = "copied"
+	copyStatusFailed = "failed"
+)
+
+// copyLine - A source line repriced from the current product, ready to be added to a target cart.
+type copyLine struct {
+	product *productSnapshot
+	item    models.CartItem
+}
+
+// CopyCart - Copies the lines of a student's cart into the default carts of the target students at
+// current product prices. In merge mode the lines are added to what each target holds; in replace
+// mode they take the place of it. Every target is checked and saved on its own, so one failing
+// target leaves the others copied.
+func (s *cartService) CopyCart(ctx context.Context, req *models.CopyCartRequest) (*models.CopyCartResult, error) {
+
+	teacherID, err := s.studentCartOwner(ctx, req.TeacherID, req.OwnerID, req.SourceStudentID, models.CartPermissionView)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := s.resolveCart(ctx, teacherID, req.SourceStudentID, req.SourceCartID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(source.Items) == 0 {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidOperation, "source cart is empty", nil)
+	}
+
+	result := &models.CopyCartResult{}
+
+	lines, err := s.copyLines(ctx, source, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidOperation, "none of the source cart items can be copied", result.Skipped)
+	}
+
+	seen := map[string]bool{}
+	for _, studentID := range req.TargetStudentIDs {
+		if seen[studentID] {
+			continue
+		}
+		seen[studentID] = true
+
+		target := models.CopyTargetResult{StudentID: studentID}
+
+		copied, err := s.copyToStudent(ctx, req, source, lines, studentID)
+		if err != nil {
+			target.Status = copyStatusFailed
+			target.Error, target.ErrorCode, target.Details = describeError(err)
+		} else {
+			target.Status = copyStatusCopied
+			target.Items = copied
+		}
+
+		result.Targets = append(result.Targets, target)
+	}
+
+	return result, nil
+}
+
+// copyLines - Reprices the source lines. Lines whose product is gone or whose variation was dropped
+// are recorded as skipped; other errors are returned.
+func (s *cartService) copyLines(ctx context.Context, source *models.Cart, result *models.CopyCartResult) ([]copyLine, error) {
+
+	products := map[primitive.ObjectID]*productSnapshot{}
+	lines := make([]copyLine, 0, len(source.Items))
+
+	for _, item := range source.Items {
+		product, ok := products[item.ProductID]
+		if !ok {
+			fetched, err := s.productAPI.GetProduct(ctx, item.ProductID)
+			if err != nil {
+				var serviceErr *models.ServiceError
+				if errors.As(err, &serviceErr) && serviceErr.ErrorCode == models.ErrProductNotFound {
+					result.Skipped = append(result.Skipped, skippedLine(item, "product_not_found"))
+					continue
+				}
+				return nil, err
+			}
+			product = fetched
+			products[item.ProductID] = product
+		}
+
+		cartItem, err := product.cartItem(item.Quantity, item.Variation)
+		if err != nil {
+			result.Skipped = append(result.Skipped, skippedLine(item, "invalid_variation"))
+			continue
+		}
+
+		lines = append(lines, copyLine{product: product, item: *cartItem})
+	}
+
+	return lines, nil
+}
+
+func skippedLine(item models.CartItem, reason string) models.CopySkippedLine {
+	return models.CopySkippedLine{
+		ProductID:   item.ProductID.Hex(),
+		ProductName: item.ProductName,
+		Variation:   item.Variation,
+		Reason:      reason,
+	}
+}
+
+// copyToStudent - Builds the student's new default cart in memory, running the purchase limit, stock,
+// usage budget and spending budget checks line by line, and saves it only when every line passes.
+// It returns the number of lines copied.
+func (s *cartService) copyToStudent(ctx context.Context, req *models.CopyCartRequest, source *models.Cart, lines []copyLine, studentID string) (int, error) {
+
+	if studentID == source.StudentID && !source.Named {
+		return 0, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidOperation, "a cart cannot be copied onto itself", nil)
+	}
+
+	teacherID, err := s.studentCartOwner(ctx, req.TeacherID, req.OwnerID, studentID, models.CartPermissionEdit)
+	if err != nil {
+		return 0, err
+	}
+
+	cart, err := s.repoCart.GetCartByTeacherStudent(ctx, teacherID, studentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	var replaced []models.CartItem
+	if req.Mode == copyModeReplace {
+		replaced = cart.Items
+		cart.Items = []models.CartItem{}
+	}
+
+	for _, line := range lines {
+		item := line.item
+
+		if err := s.ensureWithinPurchaseLimit(ctx, cart, line.product, item.Quantity); err != nil {
+			return 0, err
+		}
+
+		if err := s.ensureInStock(ctx, cart, line.product, &item, item.Quantity); err != nil {
+			return 0, err
+		}
+
+		if err := s.ensureWithinUsageBudget(cart, &item, item.Quantity); err != nil {
+			return 0, err
+		}
+
+		if err := s.ensureWithinBudgets(ctx, cart, &item, item.Quantity); err != nil {
+			return 0, err
+		}
+
+		cart.Items = models.MergeCartLine(cart.Items, item)
+	}
+
+	cart.CalculateTotals()
+
+	if err := s.repoCart.UpdateCart(ctx, cart); err != nil {
+		return 0, fmt.Errorf("failed to save cart: %w", err)
+	}
+
+	for _, item := range replaced {
+		if err := s.repoHistory.AddCartHistory(ctx, teacherID, studentID, item, "remove", item.Quantity); err != nil {
+			return 0, fmt.Errorf("unable to add cart history: %w", err)
+		}
+	}
+
+	for _, line := range lines {
+		if err := s.repoHistory.AddCartHistory(ctx, teacherID, studentID, line.item, "copy", line.item.Quantity); err != nil {
+			return 0, fmt.Errorf("unable to add cart history: %w", err)
+		}
+	}
+
+	if err := s.cartChanged(ctx, cart); err != nil {
+		return 0, err
+	}
+
+	return len(lines), nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"store/internal/models"
+	"store/internal/repository"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestCopyToStudent(t *testing.T) {
+	pen := &productSnapshot{ID: primitive.NewObjectID(), Name: "Pen", PriceStore: 1.5}
+	book := &productSnapshot{ID: primitive.NewObjectID(), Name: "Book", PriceStore: 5}
+	ruler := models.CartItem{ProductID: primitive.NewObjectID(), ProductName: "Ruler", Quantity: 1, PriceStore: 2}
+
+	tests := []struct {
+		name      string
+		studentID string
+		named     bool
+		mode      string
+		target    []models.CartItem
+		bookStock *int
+		wantErr   string
+		want      map[primitive.ObjectID]int
+		// wantHistory is the number of history events recorded for the target.
+		wantHistory int
+	}{
+		{
+			name:        "merge adds to the target lines",
+			studentID:   "s2",
+			target:      []models.CartItem{{ProductID: pen.ID, ProductName: "Pen", Quantity: 1, PriceStore: 1.5}, ruler},
+			want:        map[primitive.ObjectID]int{pen.ID: 3, book.ID: 1, ruler.ProductID: 1},
+			wantHistory: 2,
+		},
+		{
+			name:        "replace drops the target lines",
+			studentID:   "s2",
+			mode:        copyModeReplace,
+			target:      []models.CartItem{ruler},
+			want:        map[primitive.ObjectID]int{pen.ID: 2, book.ID: 1},
+			wantHistory: 3,
+		},
+		{
+			name:      "default cart onto itself",
+			studentID: "s1",
+			wantErr:   models.ErrInvalidOperation,
+		},
+		{
+			name:        "named cart into the student's default cart",
+			studentID:   "s1",
+			named:       true,
+			want:        map[primitive.ObjectID]int{pen.ID: 2, book.ID: 1},
+			wantHistory: 2,
+		},
+		{
+			name:      "student of another teacher",
+			studentID: "s3",
+			wantErr:   models.ErrStudentNotAssigned,
+		},
+		{
+			name:      "line out of stock leaves the target as it was",
+			studentID: "s2",
+			target:    []models.CartItem{ruler},
+			bookStock: new(int),
+			wantErr:   models.ErrInsufficientStock,
+		},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			for i := 0; i < tt.wantHistory; i++ {
+				mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+			}
+
+			repo := newFakeCartRepository()
+			target := models.Cart{TeacherID: "t1", StudentID: tt.studentID, Items: tt.target}
+			target.CalculateTotals()
+			target = repo.put(target)
+
+			s := &cartService{
+				repoCart:    repo,
+				repoHistory: *repository.NewCartHistoryRepository(mt.Coll, mt.Coll),
+				budgets:     &fakeBudgetRepository{},
+				students:    &countingDirectory{links: map[string]bool{"t1|s1": true, "t1|s2": true}},
+			}
+
+			bookSnapshot := *book
+			bookSnapshot.Stock = tt.bookStock
+			lines := []copyLine{
+				{product: pen, item: models.CartItem{ProductID: pen.ID, ProductName: "Pen", Quantity: 2, PriceStore: 1.5}},
+				{product: &bookSnapshot, item: models.CartItem{ProductID: book.ID, ProductName: "Book", Quantity: 1, PriceStore: 5}},
+			}
+			source := &models.Cart{ID: primitive.NewObjectID(), TeacherID: "t1", StudentID: "s1", Named: tt.named}
+			req := &models.CopyCartRequest{TeacherID: "t1", Mode: tt.mode}
+
+			copied, err := s.copyToStudent(context.Background(), req, source, lines, tt.studentID)
+
+			if tt.wantErr != "" {
+				var serviceErr *models.ServiceError
+				if !errors.As(err, &serviceErr) || serviceErr.ErrorCode != tt.wantErr {
+					mt.Fatalf("copyToStudent() error = %v, want %s", err, tt.wantErr)
+				}
+				if stored := repo.get(target.ID); !reflect.DeepEqual(stored.Items, target.Items) {
+					mt.Errorf("target items = %+v, want %+v kept", stored.Items, target.Items)
+				}
+				return
+			}
+			if err != nil {
+				mt.Fatalf("copyToStudent() error = %v", err)
+			}
+			if copied != len(lines) {
+				mt.Errorf("copied = %d, want %d", copied, len(lines))
+			}
+
+			got := map[primitive.ObjectID]int{}
+			for _, item := range repo.get(target.ID).Items {
+				got[item.ProductID] = item.Quantity
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				mt.Errorf("target quantities = %v, want %v", got, tt.want)
+			}
+
+			if events := len(mt.GetAllStartedEvents()); events != tt.wantHistory {
+				mt.Errorf("history events = %d, want %d", events, tt.wantHistory)
+			}
+		})
+	}
+}
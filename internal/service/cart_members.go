@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"store/internal/models"
+	"store/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// cartOwner - Resolves whose carts a request works on. An empty ownerID, or the caller's own ID,
+// means the caller's carts; otherwise the owner must have granted the caller the permission for the
+// student, or for all of their students when studentID is empty.
+func (s *cartService) cartOwner(ctx context.Context, teacherID string, ownerID string, studentID string, permission string) (string, error) {
+	return cartOwner(ctx, s.members, teacherID, ownerID, studentID, permission)
+}
+
+func cartOwner(ctx context.Context, members repository.CartMemberRepository, teacherID string, ownerID string, studentID string, permission string) (string, error) {
+	if ownerID == "" || ownerID == teacherID {
+		return teacherID, nil
+	}
+
+	grants, err := members.GetMemberships(ctx, ownerID, teacherID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cart members: %w", err)
+	}
+
+	for _, grant := range grants {
+		if grant.Covers(studentID) && grant.Allows(permission) {
+			return ownerID, nil
+		}
+	}
+
+	return "", models.NewServiceError(http.StatusForbidden, models.ErrCartAccessDenied, fmt.Sprintf("no %s access to the carts of teacher %s", permission, ownerID), nil)
+}
+
+// studentCartOwner - cartOwner for one student's carts, which must also be the owner's student.
+func (s *cartService) studentCartOwner(ctx context.Context, teacherID string, ownerID string, studentID string, permission string) (string, error) {
+	owner, err := s.cartOwner(ctx, teacherID, ownerID, studentID, permission)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.ensureTeacherOfStudent(ctx, owner, studentID); err != nil {
+		return "", err
+	}
+
+	return owner, nil
+}
+
+// ownerOrSelf - The teacher whose carts to load before the caller's access to them can be checked.
+func ownerOrSelf(teacherID string, ownerID string) string {
+	if ownerID == "" {
+		return teacherID
+	}
+	return ownerID
+}
+
+func (s *cartService) GetCartMembers(ctx context.Context, teacherID string) ([]models.CartMember, error) {
+	return s.members.GetMembersByOwner(ctx, teacherID)
+}
+
+func (s *cartService) GetCartMemberships(ctx context.Context, teacherID string) ([]models.CartMember, error) {
+	return s.members.GetMembershipsByMember(ctx, teacherID)
+}
+
+// GrantCartAccess - Gives another teacher access to the caller's carts.
+func (s *cartService) GrantCartAccess(ctx context.Context, req *models.GrantCartAccessRequest) (*models.CartMember, error) {
+
+	if req.MemberID == req.TeacherID {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidOperation, "teachers already have full access to their own carts", nil)
+	}
+
+	if req.StudentID != "" {
+		if err := s.ensureTeacherOfStudent(ctx, req.TeacherID, req.StudentID); err != nil {
+			return nil, err
+		}
+	}
+
+	member := &models.CartMember{
+		OwnerID:    req.TeacherID,
+		MemberID:   req.MemberID,
+		StudentID:  req.StudentID,
+		Permission: req.Permission,
+	}
+
+	if err := s.members.UpsertMember(ctx, member); err != nil {
+		return nil, fmt.Errorf("failed to save cart member: %w", err)
+	}
+
+	return member, nil
+}
+
+// RevokeCartAccess - Removes the grant for the student, or the grant for all students when studentID is empty.
+func (s *cartService) RevokeCartAccess(ctx context.Context, teacherID string, memberID string, studentID string) error {
+
+	deleted, err := s.members.DeleteMember(ctx, teacherID, memberID, studentID)
+	if err != nil {
+		return fmt.Errorf("failed to delete cart member: %w", err)
+	}
+
+	if !deleted {
+		return models.NewServiceError(http.StatusNotFound, models.ErrCartMemberNotFound, "cart member not found", nil)
+	}
+
+	return nil
+}
+
+// GetSharedCarts - The default carts other teachers share with the teacher, each marked with its owner
+// and the highest permission the teacher holds on it.
+func (s *cartService) GetSharedCarts(ctx context.Context, teacherID string) ([]bson.M, error) {
+
+	memberships, err := s.members.GetMembershipsByMember(ctx, teacherID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart memberships: %w", err)
+	}
+
+	byOwner := map[string][]models.CartMember{}
+	var owners []string
+	for _, membership := range memberships {
+		if _, ok := byOwner[membership.OwnerID]; !ok {
+			owners = append(owners, membership.OwnerID)
+		}
+		byOwner[membership.OwnerID] = append(byOwner[membership.OwnerID], membership)
+	}
+
+	shared := []bson.M{}
+	for _, ownerID := range owners {
+		carts, err := s.repoCart.GetCartByTeacher(ctx, ownerID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, cart := range carts {
+			studentID, _ := cart["_id"].(string)
+
+			permission := ""
+			for _, grant := range byOwner[ownerID] {
+				if grant.Covers(studentID) && (permission == "" || grant.Allows(permission)) {
+					permission = grant.Permission
+				}
+			}
+			if permission == "" {
+				continue
+			}
+
+			cart["owner_id"] = ownerID
+			cart["permission"] = permission
+			shared = append(shared, cart)
+		}
+	}
+
+	return shared, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"store/internal/models"
+	"testing"
+)
+
+func TestCartOwnerPermissions(t *testing.T) {
+	permissions := []string{models.CartPermissionView, models.CartPermissionEdit, models.CartPermissionCheckout}
+
+	// allowed[granted][required] - Each permission includes the ones before it.
+	allowed := map[string]map[string]bool{
+		models.CartPermissionView:     {models.CartPermissionView: true},
+		models.CartPermissionEdit:     {models.CartPermissionView: true, models.CartPermissionEdit: true},
+		models.CartPermissionCheckout: {models.CartPermissionView: true, models.CartPermissionEdit: true, models.CartPermissionCheckout: true},
+	}
+
+	for _, granted := range permissions {
+		for _, required := range permissions {
+			t.Run(granted+" grant, "+required+" request", func(t *testing.T) {
+				members := &fakeCartMemberRepository{grants: []models.CartMember{
+					{OwnerID: "owner", MemberID: "member", StudentID: "s1", Permission: granted},
+				}}
+
+				owner, err := cartOwner(context.Background(), members, "member", "owner", "s1", required)
+
+				if allowed[granted][required] {
+					if err != nil || owner != "owner" {
+						t.Errorf("cartOwner() = %q, %v, want the owner", owner, err)
+					}
+					return
+				}
+
+				var serviceErr *models.ServiceError
+				if !errors.As(err, &serviceErr) || serviceErr.ErrorCode != models.ErrCartAccessDenied {
+					t.Errorf("cartOwner() error = %v, want %s", err, models.ErrCartAccessDenied)
+				}
+			})
+		}
+	}
+}
+
+func TestCartOwnerScope(t *testing.T) {
+	members := &fakeCartMemberRepository{grants: []models.CartMember{
+		{OwnerID: "owner", MemberID: "member", StudentID: "s1", Permission: models.CartPermissionCheckout},
+		{OwnerID: "owner", MemberID: "member", Permission: models.CartPermissionView},
+	}}
+
+	tests := []struct {
+		name       string
+		teacherID  string
+		ownerID    string
+		studentID  string
+		permission string
+		want       string
+	}{
+		{name: "own carts", teacherID: "member", permission: models.CartPermissionCheckout, want: "member"},
+		{name: "owner is the caller", teacherID: "owner", ownerID: "owner", studentID: "s2", permission: models.CartPermissionCheckout, want: "owner"},
+		{name: "student grant", teacherID: "member", ownerID: "owner", studentID: "s1", permission: models.CartPermissionCheckout, want: "owner"},
+		{name: "all-students grant", teacherID: "member", ownerID: "owner", studentID: "s2", permission: models.CartPermissionView, want: "owner"},
+		{name: "student grant does not cover another student", teacherID: "member", ownerID: "owner", studentID: "s2", permission: models.CartPermissionEdit},
+		{name: "student grant does not cover all students", teacherID: "member", ownerID: "owner", permission: models.CartPermissionEdit},
+		{name: "other member", teacherID: "stranger", ownerID: "owner", studentID: "s1", permission: models.CartPermissionView},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, err := cartOwner(context.Background(), members, tt.teacherID, tt.ownerID, tt.studentID, tt.permission)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("cartOwner() = %q, want access denied", owner)
+				}
+				return
+			}
+			if err != nil || owner != tt.want {
+				t.Errorf("cartOwner() = %q, %v, want %q", owner, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSharedCarts(t *testing.T) {
+	repo := newFakeCartRepository(
+		models.Cart{TeacherID: "o1", StudentID: "s1"},
+		models.Cart{TeacherID: "o1", StudentID: "s2"},
+		models.Cart{TeacherID: "o2", StudentID: "s3"},
+		models.Cart{TeacherID: "o2", StudentID: "s4"},
+		models.Cart{TeacherID: "member", StudentID: "s5"},
+	)
+	members := &fakeCartMemberRepository{grants: []models.CartMember{
+		{OwnerID: "o1", MemberID: "member", Permission: models.CartPermissionView},
+		{OwnerID: "o1", MemberID: "member", StudentID: "s1", Permission: models.CartPermissionEdit},
+		{OwnerID: "o2", MemberID: "member", StudentID: "s3", Permission: models.CartPermissionCheckout},
+		{OwnerID: "o2", MemberID: "other", Permission: models.CartPermissionCheckout},
+	}}
+	s := &cartService{repoCart: repo, members: members}
+
+	carts, err := s.GetSharedCarts(context.Background(), "member")
+	if err != nil {
+		t.Fatalf("GetSharedCarts() error = %v", err)
+	}
+
+	var got []string
+	for _, cart := range carts {
+		got = append(got, cart["owner_id"].(string)+"/"+cart["_id"].(string)+"/"+cart["permission"].(string))
+	}
+	sort.Strings(got)
+
+	want := []string{"o1/s1/edit", "o1/s2/view", "o2/s3/checkout"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetSharedCarts() = %v, want %v", got, want)
+	}
+}
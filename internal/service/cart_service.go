@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"store/config"
 	"store/internal/models"
 	"store/internal/repository"
+	"store/pkg/auth"
 	"store/pkg/constants"
 	"store/pkg/consul"
 
@@ -17,25 +19,51 @@ import (
 
 type CartService interface {
 	AddToCart(ctx context.Context, req *models.AddToCartRequest) (*models.CartItem, error)
+	BulkAddToCart(ctx context.Context, req *models.BulkAddToCartRequest) (*models.BulkAddResult, error)
 	GetCartByTeacher(ctx context.Context, teacherID string) ([]bson.M, error)
 	GetAllCartGroupedByTeacher(ctx context.Context) ([]bson.M, error)
 	UpdateQuantityItem(ctx context.Context, productID string, req *models.UpdateCartItemRequest) error
-	RemoveFromCart(ctx context.Context, teacherID string, studentID string, productID string) error
-	ClearCart(ctx context.Context, teacherID string) error
+	RemoveFromCart(ctx context.Context, productID string, req *models.UserRequest) error
+	SaveForLater(ctx context.Context, productID string, req *models.UserRequest) error
+	MoveToCart(ctx context.Context, productID string, req *models.UserRequest) (*models.CartItem, error)
+	ClearCart(ctx context.Context, teacherID string, ownerID string) error
 	CheckOutCart(ctx context.Context, req *models.CheckOutCartRequest) error
-	GetCartHistoryByTeacher(ctx context.Context, teacherID string) ([]bson.M, error)
+	GetNamedCarts(ctx context.Context, teacherID string, ownerID string, studentID string) ([]models.Cart, error)
+	CreateNamedCart(ctx context.Context, req *models.CreateCartRequest) (*models.Cart, error)
+	RenameCart(ctx context.Context, cartID string, req *models.RenameCartRequest) (*models.Cart, error)
+	DeleteCart(ctx context.Context, teacherID string, ownerID string, cartID string) error
+	CheckOutNamedCart(ctx context.Context, cartID string, req *models.CheckOutCartRequest) error
+	CopyCart(ctx context.Context, req *models.CopyCartRequest) (*models.CopyCartResult, error)
+	TransferCarts(ctx context.Context, req *models.TransferCartsRequest) (*models.TransferResult, error)
+	AdminTransferCarts(ctx context.Context, req *models.TransferCartsRequest) (*models.TransferResult, error)
+	GetCartHistoryByTeacher(ctx context.Context, teacherID string, filter models.CartHistoryFilter) ([]bson.M, error)
+	GetCartMembers(ctx context.Context, teacherID string) ([]models.CartMember, error)
+	GetCartMemberships(ctx context.Context, teacherID string) ([]models.CartMember, error)
+	GetSharedCarts(ctx context.Context, teacherID string) ([]bson.M, error)
+	GrantCartAccess(ctx context.Context, req *models.GrantCartAccessRequest) (*models.CartMember, error)
+	RevokeCartAccess(ctx context.Context, teacherID string, memberID string, studentID string) error
+	SubmitCart(ctx context.Context, req *models.SubmitCartRequest) (*models.Cart, error)
+	GetCartsForApproval(ctx context.Context, status string) ([]models.Cart, error)
+	ApproveCart(ctx context.Context, cartID string, req *models.CartDecisionRequest) (*models.Cart, error)
+	RejectCart(ctx context.Context, cartID string, req *models.CartDecisionRequest) (*models.Cart, error)
 }
 
 type cartService struct {
 	repoCart    repository.CartRepository
 	repoHistory repository.CartHistoryRepository
+	members     repository.CartMemberRepository
+	budgets     repository.BudgetRepository
 	productAPI  *callAPI
 	orderAPI    *callAPI
+	students    StudentDirectory
+	cartConfig  config.CartConfig
 }
 
 type callAPI struct {
 	client       consul.ServiceDiscovery
 	clientServer *api.CatalogService
+	serviceName  string
+	signer       *auth.ServiceTokenSigner
 }
 
 var (
@@ -43,93 +71,115 @@ var (
 	orderService   = "order-service"
 )
 
-func NewCartService(repo repository.CartRepository, repoHistory repository.CartHistoryRepository, client *api.Client) CartService {
+func NewCartService(repo repository.CartRepository, repoHistory repository.CartHistoryRepository, members repository.CartMemberRepository, budgets repository.BudgetRepository, client *api.Client, students StudentDirectory, signer *auth.ServiceTokenSigner, cartConfig config.CartConfig) CartService {
 
-	productAPI := NewServiceAPI(client, productService)
-	orderAPI := NewServiceAPI(client, orderService)
+	productAPI := NewServiceAPI(client, productService, signer)
+	orderAPI := NewServiceAPI(client, orderService, signer)
 	return &cartService{
 		repoCart:    repo,
 		repoHistory: repoHistory,
+		members:     members,
+		budgets:     budgets,
 		productAPI:  productAPI,
 		orderAPI:    orderAPI,
+		students:    students,
+		cartConfig:  cartConfig,
 	}
 }
 
+// ensureTeacherOfStudent - Rejects cart changes for students the teacher does not teach.
+func (s *cartService) ensureTeacherOfStudent(ctx context.Context, teacherID string, studentID string) error {
+	return ensureTeacherOfStudent(ctx, s.students, teacherID, studentID)
+}
+
+func ensureTeacherOfStudent(ctx context.Context, students StudentDirectory, teacherID string, studentID string) error {
+	linked, err := students.IsTeacherOfStudent(ctx, teacherID, studentID)
+	if err != nil {
+		return models.NewServiceError(http.StatusBadGateway, models.ErrDependencyFailure, fmt.Sprintf("unable to verify teacher-student relationship: %v", err), nil)
+	}
+
+	if !linked {
+		return models.NewServiceError(http.StatusForbidden, models.ErrStudentNotAssigned, fmt.Sprintf("student %s is not assigned to teacher %s", studentID, teacherID), nil)
+	}
+
+	return nil
+}
+
 func (s *cartService) GetAllCartGroupedByTeacher(ctx context.Context) ([]bson.M, error) {
 	return s.repoCart.GetAllCartGroupedByTeacher(ctx)
 }
 
+// GetCartByTeacher - Returns the teacher's carts with the budgets that apply to them.
 func (s *cartService) GetCartByTeacher(ctx context.Context, teacherID string) ([]bson.M, error) {
-	return s.repoCart.GetCartByTeacher(ctx, teacherID)
+
+	carts, err := s.repoCart.GetCartByTeacher(ctx, teacherID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.withBudgets(ctx, teacherID, carts); err != nil {
+		return nil, err
+	}
+
+	return carts, nil
 }
 
 func (s *cartService) AddToCart(ctx context.Context, req *models.AddToCartRequest) (*models.CartItem, error) {
-	var topic string
-	var imageURL string
-	var category string
 	productID, err := primitive.ObjectIDFromHex(req.ProductID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid product ID format: %v", err)
 	}
 
-	productRes := s.productAPI.GetProductByID(req.ProductID)
+	teacherID, err := s.studentCartOwner(ctx, req.TeacherID, req.OwnerID, req.StudentID, models.CartPermissionEdit)
+	if err != nil {
+		return nil, err
+	}
 
-	if productRes == nil {
-		return nil, fmt.Errorf("product not found")
+	cart, err := s.resolveCart(ctx, teacherID, req.StudentID, req.CartID)
+	if err != nil {
+		return nil, err
 	}
-	product := productRes["data"].(map[string]interface{})
-	name := product["product_name"].(string)
-	priceStore := product["original_price_store"].(float64)
-	priceService := product["original_price_service"].(float64)
-	if product["cover_image"] == nil {
-		imageURL = ""
-	} else {
-		imageURL = product["cover_image"].(string)
+
+	product, err := s.productAPI.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, err
 	}
-	if rawTopic, ok := product["topic"]; ok && rawTopic != nil {
-		topicMap, ok := rawTopic.(map[string]interface{})
-		if ok {
-			topic = topicMap["topic_name"].(string)
-		}
-	} else {
-		topic = ""
+
+	cartItem, err := product.cartItem(req.Quantity, req.Variation)
+	if err != nil {
+		return nil, err
 	}
 
-	if rawCategory, ok := product["category"]; ok && rawCategory != nil {
-		categoryMap, ok := rawCategory.(map[string]interface{})
-		if ok {
-			category = categoryMap["category_name"].(string)
-		}
-	} else {
-		category = ""
+	if err := s.ensureWithinPurchaseLimit(ctx, cart, product, req.Quantity); err != nil {
+		return nil, err
 	}
 
-	cartItem := &models.CartItem{
-		ProductID:    productID,
-		Quantity:     req.Quantity,
-		ProductName:  name,
-		TopicName:    topic,
-		CategoryName: category,
-		PriceStore:   priceStore,
-		PriceService: priceService,
-		ImageURL:     imageURL,
+	if err := s.ensureInStock(ctx, cart, product, cartItem, req.Quantity); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureWithinUsageBudget(cart, cartItem, req.Quantity); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureWithinBudgets(ctx, cart, cartItem, req.Quantity); err != nil {
+		return nil, err
 	}
 
-	if err = s.repoCart.AddItemToCart(ctx, req.TeacherID, req.StudentID, *cartItem); err != nil {
+	if err = s.repoCart.AddItemToCart(ctx, cart, *cartItem); err != nil {
 		return nil, err
 	}
 
-	if err = s.repoHistory.AddCartHistory(ctx, req.TeacherID, req.StudentID, productID, "add", req.Quantity); err != nil {
+	if err = s.repoHistory.AddCartHistory(ctx, teacherID, req.StudentID, *cartItem, "add", req.Quantity); err != nil {
 		return nil, fmt.Errorf("unable to add cart history: %w", err)
 	}
 
-	cart, err := s.repoCart.GetCartByTeacherStudent(ctx, req.TeacherID, req.StudentID)
-	if err != nil {
-		return nil, fmt.Errorf("error retrieving updated cart: %w", err)
+	if err := s.cartChanged(ctx, cart); err != nil {
+		return nil, err
 	}
 
 	for _, item := range cart.Items {
-		if item.ProductID == productID {
+		if item.SameLine(productID, req.Variation) {
 			return &item, nil
 		}
 	}
@@ -139,71 +189,73 @@ func (s *cartService) AddToCart(ctx context.Context, req *models.AddToCartReques
 
 func (s *cartService) UpdateQuantityItem(ctx context.Context, productID string, req *models.UpdateCartItemRequest) error {
 	
-	var topic string
-	var imageURL string
-	var category string
 	var quantity int
 	id, err := primitive.ObjectIDFromHex(productID)
 	if err != nil {
 		return fmt.Errorf("invalid product ID format: %v", err)
 	}
-	
-
-	productRes := s.productAPI.GetProductByID(productID)
 
-	if productRes == nil {
-		return fmt.Errorf("product not found")
+	teacherID, err := s.studentCartOwner(ctx, req.TeacherID, req.OwnerID, req.StudentID, models.CartPermissionEdit)
+	if err != nil {
+		return err
 	}
 
-	product := productRes["data"].(map[string]interface{})
-	name := product["product_name"].(string)
-	priceStore := product["original_price_store"].(float64)
-	priceService := product["original_price_service"].(float64)
-	if product["cover_image"] == nil {
-		imageURL = ""
-	} else {
-		imageURL = product["cover_image"].(string)
-	}
-	if rawTopic, ok := product["topic"]; ok && rawTopic != nil {
-		topicMap, ok := rawTopic.(map[string]interface{})
-		if ok {
-			topic = topicMap["topic_name"].(string)
-		}
-	} else {
-		topic = ""
+	cart, err := s.resolveCart(ctx, teacherID, req.StudentID, req.CartID)
+	if err != nil {
+		return err
 	}
 
-	if rawCategory, ok := product["category"]; ok && rawCategory != nil {
-		categoryMap, ok := rawCategory.(map[string]interface{})
-		if ok {
-			category = categoryMap["category_name"].(string)
-		}
-	} else {
-		category = ""
+	product, err := s.productAPI.GetProduct(ctx, id)
+	if err != nil {
+		return err
 	}
 
 	if req.Quantity == nil {
 		quantity = 1
 	} else {
 		quantity = *req.Quantity
-		fmt.Printf("Quantity: %d\n", quantity)
 	}
 
-	cartItem := &models.CartItem{
-		ProductID:    id,
-		Quantity:     quantity,
-		ProductName:  name,
-		TopicName:    topic,
-		CategoryName: category,
-		PriceStore:   priceStore,
-		PriceService: priceService,
-		ImageURL:     imageURL,
+	cartItem, err := product.cartItem(quantity, req.Variation)
+	if err != nil {
+		return err
 	}
 
-	return s.repoCart.UpdateCartItemQuantity(ctx, req.TeacherID, req.StudentID, id, quantity, req.Type, *cartItem)
+	if req.Type == "increase" {
+		// An existing line grows by one; a new line is added with the requested quantity.
+		requested := quantity
+		for _, item := range cart.Items {
+			if item.SameLine(id, req.Variation) {
+				requested = 1
+				break
+			}
+		}
+
+		if err := s.ensureWithinPurchaseLimit(ctx, cart, product, requested); err != nil {
+			return err
+		}
+
+		if err := s.ensureInStock(ctx, cart, product, cartItem, requested); err != nil {
+			return err
+		}
+
+		if err := s.ensureWithinUsageBudget(cart, cartItem, requested); err != nil {
+			return err
+		}
+
+		if err := s.ensureWithinBudgets(ctx, cart, cartItem, requested); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repoCart.UpdateCartItemQuantity(ctx, cart, id, quantity, req.Type, *cartItem); err != nil {
+		return err
+	}
+
+	return s.cartChanged(ctx, cart)
 }
 
-func (s *cartService) RemoveFromCart(ctx context.Context, teacherID string, studentID string, productID string) error {
+func (s *cartService) RemoveFromCart(ctx context.Context, productID string, req *models.UserRequest) error {
 
 	id, err := primitive.ObjectIDFromHex(productID)
 
@@ -211,35 +263,193 @@ func (s *cartService) RemoveFromCart(ctx context.Context, teacherID string, stud
 		return fmt.Errorf("invalid product ID")
 	}
 
-	cart, err := s.repoCart.GetCartByTeacherStudent(ctx, teacherID, studentID)
+	teacherID, err := s.studentCartOwner(ctx, req.TeacherID, req.OwnerID, req.StudentID, models.CartPermissionEdit)
+	if err != nil {
+		return err
+	}
+
+	cart, err := s.resolveCart(ctx, teacherID, req.StudentID, req.CartID)
 
 	if err != nil {
-		return fmt.Errorf("failed to get cart: %w", err)
+		return err
 	}
 
-	var quantity int
+	var removed *models.CartItem
+
+	for i, item := range cart.Items {
+		if item.SameLine(id, req.Variation) {
+			removed = &cart.Items[i]
+			break
+		}
+	}
+
+	if removed == nil {
+		return fmt.Errorf("product not found in cart")
+	}
+
+	if err = s.repoHistory.AddCartHistory(ctx, teacherID, req.StudentID, *removed, "remove", removed.Quantity); err != nil {
+		return fmt.Errorf("unable to add cart history: %w", err)
+	}
+
+	if err := s.repoCart.RemoveFromCart(ctx, cart, id, req.Variation); err != nil {
+		return err
+	}
+
+	return s.cartChanged(ctx, cart)
+}
+
+// SaveForLater - Parks an active line in the cart's saved list.
+func (s *cartService) SaveForLater(ctx context.Context, productID string, req *models.UserRequest) error {
+
+	id, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return fmt.Errorf("invalid product ID")
+	}
+
+	teacherID, err := s.studentCartOwner(ctx, req.TeacherID, req.OwnerID, req.StudentID, models.CartPermissionEdit)
+	if err != nil {
+		return err
+	}
+
+	cart, err := s.resolveCart(ctx, teacherID, req.StudentID, req.CartID)
+	if err != nil {
+		return err
+	}
+
+	var saved models.CartItem
+	found := false
 
 	for _, item := range cart.Items {
-		if item.ProductID == id {
+		if item.SameLine(id, req.Variation) {
+			saved = item
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return models.NewServiceError(http.StatusNotFound, models.ErrCartItemNotFound, "product not found in cart", nil)
+	}
+
+	if err := s.repoCart.SaveForLater(ctx, cart, id, req.Variation); err != nil {
+		return err
+	}
+
+	if err = s.repoHistory.AddCartHistory(ctx, teacherID, req.StudentID, saved, "save_for_later", saved.Quantity); err != nil {
+		return fmt.Errorf("unable to add cart history: %w", err)
+	}
+
+	return s.cartChanged(ctx, cart)
+}
+
+// MoveToCart - Returns a saved line to the active items. The line is repriced and goes through
+// the same purchase limit, stock and usage budget checks as a new item.
+func (s *cartService) MoveToCart(ctx context.Context, productID string, req *models.UserRequest) (*models.CartItem, error) {
+
+	id, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product ID")
+	}
+
+	teacherID, err := s.studentCartOwner(ctx, req.TeacherID, req.OwnerID, req.StudentID, models.CartPermissionEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := s.resolveCart(ctx, teacherID, req.StudentID, req.CartID)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity := 0
+
+	for _, item := range cart.SavedItems {
+		if item.SameLine(id, req.Variation) {
 			quantity = item.Quantity
 			break
 		}
 	}
 
 	if quantity == 0 {
-		return fmt.Errorf("product not found in cart")
+		return nil, models.NewServiceError(http.StatusNotFound, models.ErrCartItemNotFound, "product not found in saved items", nil)
 	}
 
-	if err = s.repoHistory.AddCartHistory(ctx, teacherID, studentID, id, "remove", quantity); err != nil {
-		return fmt.Errorf("unable to add cart history: %w", err)
+	product, err := s.productAPI.GetProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cartItem, err := product.cartItem(quantity, req.Variation)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureWithinPurchaseLimit(ctx, cart, product, quantity); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureInStock(ctx, cart, product, cartItem, quantity); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureWithinUsageBudget(cart, cartItem, quantity); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureWithinBudgets(ctx, cart, cartItem, quantity); err != nil {
+		return nil, err
+	}
+
+	if err := s.repoCart.MoveToCart(ctx, cart, *cartItem); err != nil {
+		return nil, err
+	}
+
+	if err = s.repoHistory.AddCartHistory(ctx, teacherID, req.StudentID, *cartItem, "move_to_cart", quantity); err != nil {
+		return nil, fmt.Errorf("unable to add cart history: %w", err)
+	}
+
+	if err := s.cartChanged(ctx, cart); err != nil {
+		return nil, err
+	}
+
+	for _, item := range cart.Items {
+		if item.SameLine(id, req.Variation) {
+			return &item, nil
+		}
+	}
+
+	return nil, fmt.Errorf("item not found in cart")
+}
+
+// ClearCart - Empties the default carts of the teacher, or of the owner who shared them, which
+// takes edit access to all of the owner's carts. The emptied carts go back to draft.
+func (s *cartService) ClearCart(ctx context.Context, teacherID string, ownerID string) error {
+
+	owner, err := s.cartOwner(ctx, teacherID, ownerID, "", models.CartPermissionEdit)
+	if err != nil {
+		return err
+	}
+
+	carts, err := s.repoCart.GetCartsByTeacher(ctx, owner)
+	if err != nil {
+		return fmt.Errorf("failed to get carts: %w", err)
+	}
+
+	// The carts are reopened as read, before clearing saves them again.
+	for i := range carts {
+		if err := s.reopenCart(ctx, &carts[i]); err != nil {
+			return err
+		}
 	}
 
-	return s.repoCart.RemoveFromCart(ctx, teacherID, studentID, id)
+	return s.clearCart(ctx, owner, "clear")
 }
 
-func (s *cartService) ClearCart(ctx context.Context, teacherID string) error {
+// clearCart - Empties the teacher's default carts, recording each line as an eventType event: "order"
+// after a checkout, "clear" otherwise. Only "order" events count towards purchase limits and budgets.
+func (s *cartService) clearCart(ctx context.Context, teacherID string, eventType string) error {
 
-	err := s.repoHistory.AddAllCartHistory(ctx, teacherID)
+	err := s.repoHistory.AddAllCartHistory(ctx, teacherID, eventType)
 	if err != nil {
 		return fmt.Errorf("unable to add all cart history: %w", err)
 	}
@@ -247,33 +457,86 @@ func (s *cartService) ClearCart(ctx context.Context, teacherID string) error {
 	return s.repoCart.ClearCart(ctx, teacherID)
 }
 
+// CheckOutCart - Orders the default carts of the teacher, or of the owner who shared them, which
+// takes checkout access to all of the owner's carts.
 func (s *cartService) CheckOutCart(ctx context.Context, req *models.CheckOutCartRequest) error {
 
-	if req.Email == "" {
-		return fmt.Errorf("email cannot be empty")
+	teacherID, err := s.cartOwner(ctx, req.TeacherID, req.OwnerID, "", models.CartPermissionCheckout)
+	if err != nil {
+		return err
 	}
 
-	if req.Types == "" {
-		return fmt.Errorf("type cannot be empty")
+	carts, err := s.repoCart.GetCartsByTeacher(ctx, teacherID)
+	if err != nil {
+		return fmt.Errorf("failed to get carts: %w", err)
 	}
 
-	if req.Street == "" {
-		return fmt.Errorf("street cannot be empty")
+	if err := s.checkOut(ctx, teacherID, req, carts, false); err != nil {
+		return err
 	}
 
-	if req.City == "" {
-		return fmt.Errorf("city cannot be empty")
+	// The carts are marked as read, before clearing saves them again. They are cleared even when
+	// marking fails, so that the ordered lines cannot be ordered twice.
+	markErr := s.markOrdered(ctx, carts)
+
+	if err := s.clearCart(ctx, teacherID, "order"); err != nil {
+		return fmt.Errorf("order created, but failed to clear cart: %v", err)
 	}
 
-	if req.Country == "" {
-		return fmt.Errorf("country cannot be empty")
+	if markErr != nil {
+		return fmt.Errorf("order created, but failed to update cart status: %v", markErr)
 	}
 
-	if req.Phone == "" {
-		return fmt.Errorf("phone cannot be empty")
+	return nil
+}
+
+// checkOut - Reprices the carts of teacherID, runs the pre-order checks, approvals and budgets included,
+// and places the order for them. With listLines set the carts' lines are sent to order-service
+// explicitly, as for a named cart; otherwise order-service orders the default carts teacher-wide.
+func (s *cartService) checkOut(ctx context.Context, teacherID string, req *models.CheckOutCartRequest, carts []models.Cart, listLines bool) error {
+
+	for _, cart := range carts {
+		if len(cart.Items) == 0 {
+			continue
+		}
+		if err := s.ensureTeacherOfStudent(ctx, teacherID, cart.StudentID); err != nil {
+			return err
+		}
 	}
 
-	response, err := s.orderAPI.CreateOrderByUserID(ctx, req.TeacherID, req.Types, req.Email, req.Street, req.City, req.Country, req.Phone, req.State)
+	products, err := s.cartProducts(ctx, carts)
+	if err != nil {
+		return err
+	}
+
+	if err := s.refreshCartPrices(ctx, carts, products); err != nil {
+		return err
+	}
+
+	if err := s.ensureCartsApproved(carts); err != nil {
+		return err
+	}
+
+	if err := s.ensureCartsWithinBudgets(ctx, teacherID, carts); err != nil {
+		return err
+	}
+
+	if err := s.ensureCartsWithinPurchaseLimits(ctx, teacherID, carts, products); err != nil {
+		return err
+	}
+
+	if err := s.ensureCartsInStock(ctx, carts, products); err != nil {
+		return err
+	}
+
+	var items []models.OrderItem
+	if listLines {
+		for i := range carts {
+			items = append(items, carts[i].OrderItems()...)
+		}
+	}
+
+	response, err := s.orderAPI.CreateOrderByUserID(ctx, teacherID, req.Types, req.Email, req.Street, req.City, req.Country, req.Phone, req.State, items)
 	if err != nil {
 		return fmt.Errorf("failed to create order: %v", err)
 	}
@@ -286,14 +549,10 @@ func (s *cartService) CheckOutCart(ctx context.Context, req *models.CheckOutCart
 		}
 	}
 
-	if err := s.ClearCart(ctx, req.TeacherID); err != nil {
-		return fmt.Errorf("order created, but failed to clear cart: %v", err)
-	}
-
 	return nil
 }
 
-func NewServiceAPI(client *api.Client, serviceName string) *callAPI {
+func NewServiceAPI(client *api.Client, serviceName string, signer *auth.ServiceTokenSigner) *callAPI {
 	sd, err := consul.NewServiceDiscovery(client, serviceName)
 	if err != nil {
 		fmt.Printf("Error creating service discovery: %v\n", err)
@@ -309,12 +568,47 @@ func NewServiceAPI(client *api.Client, serviceName string) *callAPI {
 	return &callAPI{
 		client:       sd,
 		clientServer: service,
+		serviceName:  serviceName,
+		signer:       signer,
 	}
 }
 
-func (c *callAPI) GetProductByID(productID string) map[string]interface{} {
+// authHeaders - Builds the Authorization header for an outbound call. With a signer configured a
+// short-lived service token is minted, carrying the acting user as a separate claim; otherwise the
+// caller's own token is forwarded when there is one.
+func (c *callAPI) authHeaders(ctx context.Context) (map[string]string, error) {
+	headers := map[string]string{}
+
+	if c.signer != nil {
+		var userID string
+		if actor := models.ActorFromContext(ctx); actor != nil {
+			userID = actor.UserID
+		}
+
+		token, err := c.signer.Mint(c.serviceName, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to mint service token: %w", err)
+		}
+		headers["Authorization"] = "Bearer " + token
+		return headers, nil
+	}
+
+	if token, ok := ctx.Value(constants.TokenKey).(string); ok && token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+
+	return headers, nil
+}
+
+func (c *callAPI) GetProductByID(ctx context.Context, productID string) map[string]interface{} {
+	headers, err := c.authHeaders(ctx)
+	if err != nil {
+		fmt.Printf("Error preparing request: %v\n", err)
+		return nil
+	}
+
 	endpoint := fmt.Sprintf("/api/v1/products/%s", productID)
-	res, err := c.client.CallAPI(c.clientServer, endpoint, http.MethodGet, nil, nil)
+	res, err := c.client.CallAPI(c.clientServer, endpoint, http.MethodGet, nil, headers)
 	if err != nil {
 		fmt.Printf("Error calling API: %v\n", err)
 		return nil
@@ -333,9 +627,9 @@ func (c *callAPI) GetProductByID(productID string) map[string]interface{} {
 	return myMap
 }
 
-func (c *callAPI) CreateOrderByUserID(ctx context.Context, userID, types, email, street, city, country, phone string, state *string) (interface{}, error) {
+func (c *callAPI) CreateOrderByUserID(ctx context.Context, userID, types, email, street, city, country, phone string, state *string, items []models.OrderItem) (interface{}, error) {
 
-	requestBody := map[string]string{
+	requestBody := map[string]interface{}{
 		"teacher_id": userID,
 		"email":      email,
 		"types":      types,
@@ -349,6 +643,10 @@ func (c *callAPI) CreateOrderByUserID(ctx context.Context, userID, types, email,
 		requestBody["state"] = *state
 	}
 
+	if len(items) > 0 {
+		requestBody["items"] = items
+	}
+
 	// Chuyển đổi dữ liệu thành JSON
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
@@ -356,10 +654,11 @@ func (c *callAPI) CreateOrderByUserID(ctx context.Context, userID, types, email,
 	}
 
 	// Thiết lập headers
-	headers := map[string]string{
-		"Content-Type":  "application/json",
-		"Authorization": "Bearer " + ctx.Value(constants.TokenKey).(string),
+	headers, err := c.authHeaders(ctx)
+	if err != nil {
+		return nil, err
 	}
+	headers["Content-Type"] = "application/json"
 
 	// Gọi API sử dụng phương thức POST
 	endpoint := "/api/v1/orders/items"
@@ -378,6 +677,6 @@ func (c *callAPI) CreateOrderByUserID(ctx context.Context, userID, types, email,
 	return responseData, nil
 }
 
-func (s *cartService) GetCartHistoryByTeacher(ctx context.Context, teacherID string) ([]bson.M, error) {
-	return s.repoCart.GetCartHistoryByTeacher(ctx, teacherID)
+func (s *cartService) GetCartHistoryByTeacher(ctx context.Context, teacherID string, filter models.CartHistoryFilter) ([]bson.M, error) {
+	return s.repoCart.GetCartHistoryByTeacher(ctx, teacherID, filter)
 }
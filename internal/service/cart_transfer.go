@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"store/internal/models"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	transferConflictSum        = "sum"
+	transferConflictKeepSource = "keep_source"
+
+	transferStatusTransferred = "transferred"
+	transferStatusSkipped     = "skipped"
+	transferStatusFailed      = "failed"
+)
+
+// TransferCarts - Moves students' carts from the caller, or from a teacher who granted the caller edit
+// access to them, to another teacher.
+func (s *cartService) TransferCarts(ctx context.Context, req *models.TransferCartsRequest) (*models.TransferResult, error) {
+
+	if req.FromTeacherID == "" {
+		req.FromTeacherID = req.TeacherID
+	}
+
+	return s.transferCarts(ctx, req, true)
+}
+
+// AdminTransferCarts - Moves students' carts between any two teachers.
+func (s *cartService) AdminTransferCarts(ctx context.Context, req *models.TransferCartsRequest) (*models.TransferResult, error) {
+
+	if req.FromTeacherID == "" {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidRequest, "from_teacher_id is required", nil)
+	}
+
+	return s.transferCarts(ctx, req, false)
+}
+
+// transferCarts - Moves the carts student by student. The target teacher must teach the student.
+// A student that fails keeps the carts not yet moved with the source teacher and does not stop the
+// others.
+func (s *cartService) transferCarts(ctx context.Context, req *models.TransferCartsRequest, checkAccess bool) (*models.TransferResult, error) {
+
+	if req.FromTeacherID == req.ToTeacherID {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidOperation, "carts cannot be transferred to the teacher they belong to", nil)
+	}
+
+	result := &models.TransferResult{
+		FromTeacherID: req.FromTeacherID,
+		ToTeacherID:   req.ToTeacherID,
+		Students:      make([]models.TransferStudentResult, 0, len(req.StudentIDs)),
+	}
+
+	seen := map[string]bool{}
+	for _, studentID := range req.StudentIDs {
+		if seen[studentID] {
+			continue
+		}
+		seen[studentID] = true
+
+		student := models.TransferStudentResult{StudentID: studentID}
+
+		var err error
+		if checkAccess {
+			_, err = s.cartOwner(ctx, req.TeacherID, req.FromTeacherID, studentID, models.CartPermissionEdit)
+		}
+		if err == nil {
+			err = s.transferStudent(ctx, req, studentID, &student)
+		}
+
+		if err != nil {
+			student.Status = transferStatusFailed
+			student.Error, student.ErrorCode, student.Details = describeError(err)
+		}
+
+		result.Students = append(result.Students, student)
+	}
+
+	return result, nil
+}
+
+func (s *cartService) transferStudent(ctx context.Context, req *models.TransferCartsRequest, studentID string, result *models.TransferStudentResult) error {
+
+	if err := s.ensureTeacherOfStudent(ctx, req.ToTeacherID, studentID); err != nil {
+		return err
+	}
+
+	carts, err := s.repoCart.GetCartsByStudent(ctx, req.FromTeacherID, studentID)
+	if err != nil {
+		return fmt.Errorf("failed to get carts: %w", err)
+	}
+
+	if len(carts) == 0 {
+		result.Status = transferStatusSkipped
+		return nil
+	}
+
+	for i := range carts {
+		source := &carts[i]
+
+		if source.Named {
+			if err := s.transferNamedCart(ctx, req, source); err != nil {
+				return err
+			}
+			result.NamedCarts++
+		} else {
+			conflicts, err := s.transferDefaultCart(ctx, req, source)
+			if err != nil {
+				return err
+			}
+			result.Conflicts += conflicts
+		}
+
+		result.Items += len(source.Items)
+	}
+
+	result.Status = transferStatusTransferred
+	return nil
+}
+
+// transferDefaultCart - Merges the source default cart into the target teacher's default cart for the
+// student and deletes it. It returns the number of lines both carts held.
+// The target is saved before the source is deleted, so a failure never loses lines; when the delete
+// fails the merged lines are taken out of the target again.
+func (s *cartService) transferDefaultCart(ctx context.Context, req *models.TransferCartsRequest, source *models.Cart) (int, error) {
+
+	target, err := s.repoCart.GetCartByTeacherStudent(ctx, req.ToTeacherID, source.StudentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	products, err := s.cartProducts(ctx, []models.Cart{*source})
+	if err != nil {
+		return 0, err
+	}
+
+	original := *target
+	original.Items = append([]models.CartItem(nil), target.Items...)
+	original.SavedItems = append([]models.CartItem(nil), target.SavedItems...)
+
+	conflicts, err := s.mergeTransfer(ctx, target, source, products, req.Conflict)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.repoCart.UpdateCart(ctx, target); err != nil {
+		return 0, fmt.Errorf("failed to save cart: %w", err)
+	}
+
+	if err := s.repoCart.DeleteCart(ctx, source); err != nil {
+		original.UpdateAt = target.UpdateAt
+		if undoErr := s.repoCart.UpdateCart(ctx, &original); undoErr != nil {
+			return 0, fmt.Errorf("failed to delete cart: %w (the merged lines could not be taken out of the target cart: %v)", err, undoErr)
+		}
+		return 0, fmt.Errorf("failed to delete cart: %w", err)
+	}
+
+	if err := s.recordTransfer(ctx, req, source); err != nil {
+		return 0, err
+	}
+
+	if err := s.cartChanged(ctx, target); err != nil {
+		return 0, err
+	}
+
+	return conflicts, nil
+}
+
+// mergeTransfer - Merges the source lines into target in memory. The lines are repriced from the
+// current products, and every line that adds units runs the purchase limit, stock, usage budget and
+// spending budget checks of an add for the receiving teacher. Saved-for-later lines are merged as
+// they are; they are checked when they are moved into the cart. It returns the number of lines both
+// carts held.
+func (s *cartService) mergeTransfer(ctx context.Context, target *models.Cart, source *models.Cart, products map[primitive.ObjectID]*productSnapshot, conflict string) (int, error) {
+
+	incoming := append([]models.CartItem(nil), source.Items...)
+	if _, err := repriceLines(incoming, products); err != nil {
+		return 0, err
+	}
+
+	itemConflicts := 0
+	for _, item := range incoming {
+		if requested := transferredUnits(target.Items, item, conflict); requested > 0 {
+			product := products[item.ProductID]
+
+			if err := s.ensureWithinPurchaseLimit(ctx, target, product, requested); err != nil {
+				return 0, err
+			}
+
+			if err := s.ensureInStock(ctx, target, product, &item, requested); err != nil {
+				return 0, err
+			}
+
+			if err := s.ensureWithinUsageBudget(target, &item, requested); err != nil {
+				return 0, err
+			}
+
+			if err := s.ensureWithinBudgets(ctx, target, &item, requested); err != nil {
+				return 0, err
+			}
+		}
+
+		var merged int
+		target.Items, merged = mergeTransferredLines(target.Items, []models.CartItem{item}, conflict)
+		itemConflicts += merged
+	}
+
+	var savedConflicts int
+	target.SavedItems, savedConflicts = mergeTransferredLines(target.SavedItems, source.SavedItems, conflict)
+
+	target.CalculateTotals()
+
+	return itemConflicts + savedConflicts, nil
+}
+
+// transferredUnits - The units that merging line adds to the target lines under the conflict policy.
+func transferredUnits(target []models.CartItem, line models.CartItem, conflict string) int {
+
+	for _, existing := range target {
+		if !existing.SameLine(line.ProductID, line.Variation) {
+			continue
+		}
+
+		switch conflict {
+		case transferConflictSum:
+			return line.Quantity
+		case transferConflictKeepSource:
+			return line.Quantity - existing.Quantity
+		}
+		return 0
+	}
+
+	return line.Quantity
+}
+
+// transferNamedCart - Hands a named cart over as it is. When the target teacher already has a cart
+// of that name for the student, a number is added to the name.
+func (s *cartService) transferNamedCart(ctx context.Context, req *models.TransferCartsRequest, source *models.Cart) error {
+
+	existing, err := s.repoCart.GetNamedCarts(ctx, req.ToTeacherID, source.StudentID)
+	if err != nil {
+		return fmt.Errorf("failed to get carts: %w", err)
+	}
+
+	taken := map[string]bool{}
+	for _, cart := range existing {
+		taken[strings.ToLower(cart.Name)] = true
+	}
+
+	name := source.Name
+	for n := 2; taken[strings.ToLower(name)]; n++ {
+		name = fmt.Sprintf("%s (%d)", source.Name, n)
+	}
+
+	if err := s.repoCart.ReassignCart(ctx, source, req.ToTeacherID, name); err != nil {
+		return fmt.Errorf("failed to transfer cart: %w", err)
+	}
+
+	return s.recordTransfer(ctx, req, source)
+}
+
+// recordTransfer - Records a "transfer_out" event for the source teacher and a "transfer_in" event
+// for the target teacher per line of the cart.
+func (s *cartService) recordTransfer(ctx context.Context, req *models.TransferCartsRequest, cart *models.Cart) error {
+
+	for _, item := range cart.Items {
+		if err := s.repoHistory.AddCartHistory(ctx, req.FromTeacherID, cart.StudentID, item, "transfer_out", item.Quantity); err != nil {
+			return fmt.Errorf("unable to add cart history: %w", err)
+		}
+		if err := s.repoHistory.AddCartHistory(ctx, req.ToTeacherID, cart.StudentID, item, "transfer_in", item.Quantity); err != nil {
+			return fmt.Errorf("unable to add cart history: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// mergeTransferredLines - Adds the source lines to the target lines. A line both hold is resolved by
+// the conflict policy: quantities are summed, or the source or the target line is kept.
+func mergeTransferredLines(target []models.CartItem, source []models.CartItem, conflict string) ([]models.CartItem, int) {
+
+	conflicts := 0
+
+	for _, line := range source {
+		found := false
+		for i := range target {
+			if !target[i].SameLine(line.ProductID, line.Variation) {
+				continue
+			}
+
+			found = true
+			conflicts++
+
+			switch conflict {
+			case transferConflictSum:
+				target[i].Quantity += line.Quantity
+			case transferConflictKeepSource:
+				target[i] = line
+			}
+			break
+		}
+
+		if !found {
+			target = append(target, line)
+		}
+	}
+
+	return target, conflicts
+}
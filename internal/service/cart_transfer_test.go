@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"store/internal/models"
+	"store/internal/repository"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMergeTransferredLines(t *testing.T) {
+	pen := primitive.NewObjectID()
+	book := primitive.NewObjectID()
+	shirt := primitive.NewObjectID()
+	small := &models.VariationSelection{VariationName: "Size", Option: "S"}
+	large := &models.VariationSelection{VariationName: "Size", Option: "L"}
+
+	target := func() []models.CartItem {
+		return []models.CartItem{
+			{ProductID: pen, Quantity: 2, PriceStore: 1},
+			{ProductID: shirt, Variation: small, Quantity: 1, PriceStore: 10},
+		}
+	}
+	source := []models.CartItem{
+		{ProductID: pen, Quantity: 3, PriceStore: 0.8},
+		{ProductID: shirt, Variation: large, Quantity: 1, PriceStore: 12},
+		{ProductID: book, Quantity: 1, PriceStore: 5},
+	}
+
+	tests := []struct {
+		name          string
+		target        []models.CartItem
+		conflict      string
+		want          []models.CartItem
+		wantConflicts int
+	}{
+		{
+			name:     "sum",
+			target:   target(),
+			conflict: transferConflictSum,
+			want: []models.CartItem{
+				{ProductID: pen, Quantity: 5, PriceStore: 1},
+				{ProductID: shirt, Variation: small, Quantity: 1, PriceStore: 10},
+				{ProductID: shirt, Variation: large, Quantity: 1, PriceStore: 12},
+				{ProductID: book, Quantity: 1, PriceStore: 5},
+			},
+			wantConflicts: 1,
+		},
+		{
+			name:     "keep source",
+			target:   target(),
+			conflict: transferConflictKeepSource,
+			want: []models.CartItem{
+				{ProductID: pen, Quantity: 3, PriceStore: 0.8},
+				{ProductID: shirt, Variation: small, Quantity: 1, PriceStore: 10},
+				{ProductID: shirt, Variation: large, Quantity: 1, PriceStore: 12},
+				{ProductID: book, Quantity: 1, PriceStore: 5},
+			},
+			wantConflicts: 1,
+		},
+		{
+			name:     "keep target",
+			target:   target(),
+			conflict: "keep_target",
+			want: []models.CartItem{
+				{ProductID: pen, Quantity: 2, PriceStore: 1},
+				{ProductID: shirt, Variation: small, Quantity: 1, PriceStore: 10},
+				{ProductID: shirt, Variation: large, Quantity: 1, PriceStore: 12},
+				{ProductID: book, Quantity: 1, PriceStore: 5},
+			},
+			wantConflicts: 1,
+		},
+		{
+			name:          "empty target",
+			conflict:      transferConflictSum,
+			want:          source,
+			wantConflicts: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := mergeTransferredLines(tt.target, source, tt.conflict)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeTransferredLines() = %+v, want %+v", got, tt.want)
+			}
+			if conflicts != tt.wantConflicts {
+				t.Errorf("conflicts = %d, want %d", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestMergeTransfer(t *testing.T) {
+	pen := primitive.NewObjectID()
+	book := primitive.NewObjectID()
+
+	source := &models.Cart{
+		TeacherID: "t1",
+		StudentID: "s1",
+		Items: []models.CartItem{
+			{ProductID: pen, Quantity: 3, PriceStore: 1},
+			{ProductID: book, Quantity: 1, PriceStore: 4},
+		},
+	}
+	budget := models.Budget{ID: primitive.NewObjectID(), Scope: models.BudgetScopeTeacher, ScopeID: "t2", Term: "spring", LimitStore: 10, Mode: models.BudgetModeReject}
+
+	tests := []struct {
+		name      string
+		target    []models.CartItem
+		other     []models.CartItem
+		bookStock int
+		penLimit  int
+		budgets   []models.Budget
+		conflict  string
+		wantErr   string
+		want      map[primitive.ObjectID]int
+	}{
+		{
+			name:      "merged at current prices",
+			target:    []models.CartItem{{ProductID: pen, Quantity: 2, PriceStore: 1.5}},
+			bookStock: 5,
+			conflict:  transferConflictSum,
+			want:      map[primitive.ObjectID]int{pen: 5, book: 1},
+		},
+		{
+			name:      "stock counts the receiving teacher's other carts",
+			other:     []models.CartItem{{ProductID: book, Quantity: 2, PriceStore: 5}},
+			bookStock: 2,
+			conflict:  transferConflictSum,
+			wantErr:   models.ErrInsufficientStock,
+		},
+		{
+			name:      "budget of the receiving teacher",
+			target:    []models.CartItem{{ProductID: pen, Quantity: 2, PriceStore: 1.5}},
+			other:     []models.CartItem{{ProductID: book, Quantity: 1, PriceStore: 5}},
+			bookStock: 5,
+			budgets:   []models.Budget{budget},
+			conflict:  transferConflictSum,
+			wantErr:   models.ErrBudgetExceeded,
+		},
+		{
+			name:      "purchase limit of the receiving teacher",
+			target:    []models.CartItem{{ProductID: pen, Quantity: 2, PriceStore: 1.5}},
+			bookStock: 5,
+			penLimit:  4,
+			conflict:  transferConflictSum,
+			wantErr:   models.ErrPurchaseLimit,
+		},
+		{
+			name:      "kept target lines add no units",
+			target:    []models.CartItem{{ProductID: pen, Quantity: 2, PriceStore: 1.5}, {ProductID: book, Quantity: 1, PriceStore: 5}},
+			bookStock: 1,
+			conflict:  "keep_target",
+			want:      map[primitive.ObjectID]int{pen: 2, book: 1},
+		},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			// One aggregation of past orders per budget and per purchase limit check.
+			for range tt.budgets {
+				mt.AddMockResponses(mtest.CreateCursorResponse(0, "store.cart_history", mtest.FirstBatch))
+			}
+			if tt.penLimit > 0 {
+				mt.AddMockResponses(mtest.CreateCursorResponse(0, "store.cart_history", mtest.FirstBatch))
+			}
+
+			other := models.Cart{TeacherID: "t2", StudentID: "s2", Items: tt.other}
+			other.CalculateTotals()
+			repo := newFakeCartRepository(other)
+			target := models.Cart{TeacherID: "t2", StudentID: "s1", Items: tt.target}
+			target.CalculateTotals()
+			target = repo.put(target)
+			s := &cartService{
+				repoCart:    repo,
+				repoHistory: *repository.NewCartHistoryRepository(mt.Coll, mt.Coll),
+				budgets:     &fakeBudgetRepository{budgets: tt.budgets},
+			}
+
+			stock := tt.bookStock
+			products := map[primitive.ObjectID]*productSnapshot{
+				pen:  {ID: pen, Name: "Pen", PriceStore: 1.5, PurchaseLimit: tt.penLimit},
+				book: {ID: book, Name: "Book", PriceStore: 5, Stock: &stock},
+			}
+
+			_, err := s.mergeTransfer(context.Background(), &target, source, products, tt.conflict)
+
+			if tt.wantErr != "" {
+				var serviceErr *models.ServiceError
+				if !errors.As(err, &serviceErr) || serviceErr.ErrorCode != tt.wantErr {
+					mt.Fatalf("mergeTransfer() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				mt.Fatalf("mergeTransfer() error = %v", err)
+			}
+
+			got := map[primitive.ObjectID]int{}
+			for _, item := range target.Items {
+				got[item.ProductID] = item.Quantity
+				if item.PriceStore != products[item.ProductID].PriceStore {
+					mt.Errorf("%s price = %v, want the current %v", products[item.ProductID].Name, item.PriceStore, products[item.ProductID].PriceStore)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				mt.Errorf("quantities = %v, want %v", got, tt.want)
+			}
+			if source.Items[0].PriceStore != 1 {
+				mt.Errorf("source lines were repriced in place")
+			}
+		})
+	}
+}
+
+func TestTransferDefaultCartSavesTargetFirst(t *testing.T) {
+	pen := models.CartItem{ProductID: primitive.NewObjectID(), Quantity: 2, PriceStore: 1}
+	book := models.CartItem{ProductID: primitive.NewObjectID(), Quantity: 1, PriceStore: 5}
+	conflict := models.NewServiceError(http.StatusConflict, models.ErrCartModified, "cart was changed by another request; reload it and try again", nil)
+
+	tests := []struct {
+		name            string
+		saveErr         error
+		deleteErr       error
+		wantErr         bool
+		wantSource      bool
+		wantTargetSaved []models.CartItem
+	}{
+		{
+			name:            "moves the lines and deletes the source",
+			wantTargetSaved: []models.CartItem{pen, book},
+		},
+		{
+			name:            "failed save keeps the source",
+			saveErr:         conflict,
+			wantErr:         true,
+			wantSource:      true,
+			wantTargetSaved: []models.CartItem{pen},
+		},
+		{
+			name:            "failed delete takes the lines out of the target",
+			deleteErr:       errors.New("connection reset"),
+			wantErr:         true,
+			wantSource:      true,
+			wantTargetSaved: []models.CartItem{pen},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeCartRepository()
+			source := repo.put(models.Cart{TeacherID: "t1", StudentID: "s1", SavedItems: []models.CartItem{book}})
+			target := repo.put(models.Cart{TeacherID: "t2", StudentID: "s1", SavedItems: []models.CartItem{pen}})
+			if tt.saveErr != nil {
+				repo.updateErrs[target.ID] = tt.saveErr
+			}
+			if tt.deleteErr != nil {
+				repo.deleteErrs[source.ID] = tt.deleteErr
+			}
+
+			s := &cartService{repoCart: repo, budgets: &fakeBudgetRepository{}}
+			req := &models.TransferCartsRequest{FromTeacherID: "t1", ToTeacherID: "t2", Conflict: transferConflictSum}
+
+			_, err := s.transferDefaultCart(context.Background(), req, &source)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("transferDefaultCart() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if _, ok := repo.carts[source.ID]; ok != tt.wantSource {
+				t.Errorf("source stored = %v, want %v", ok, tt.wantSource)
+			}
+			if saved := repo.get(target.ID).SavedItems; !reflect.DeepEqual(saved, tt.wantTargetSaved) {
+				t.Errorf("target saved items = %+v, want %+v", saved, tt.wantTargetSaved)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"store/internal/models"
+	"store/internal/repository"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeCartRepository - An in-memory CartRepository for the methods the tests exercise. Writes keep the
+// repository's optimistic concurrency: they apply only to a cart whose update_at is unchanged since it
+// was read. Calling any other method panics through the nil embedded interface.
+type fakeCartRepository struct {
+	repository.CartRepository
+
+	mu    sync.Mutex
+	carts map[primitive.ObjectID]models.Cart
+	clock time.Time
+
+	// afterFind runs after FindCart has read a cart, to change it behind the caller's back.
+	afterFind func()
+	// updateErrs fails the next write of the cart with the error, deleteErrs its next delete.
+	updateErrs map[primitive.ObjectID]error
+	deleteErrs map[primitive.ObjectID]error
+}
+
+func newFakeCartRepository(carts ...models.Cart) *fakeCartRepository {
+	r := &fakeCartRepository{
+		carts:      map[primitive.ObjectID]models.Cart{},
+		clock:      time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		updateErrs: map[primitive.ObjectID]error{},
+		deleteErrs: map[primitive.ObjectID]error{},
+	}
+	for _, cart := range carts {
+		r.put(cart)
+	}
+	return r
+}
+
+// put - Stores the cart as a new version.
+func (r *fakeCartRepository) put(cart models.Cart) models.Cart {
+	if cart.ID.IsZero() {
+		cart.ID = primitive.NewObjectID()
+	}
+	r.clock = r.clock.Add(time.Second)
+	cart.UpdateAt = r.clock
+	r.carts[cart.ID] = cloneCart(cart)
+	return cart
+}
+
+func (r *fakeCartRepository) get(id primitive.ObjectID) models.Cart {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return cloneCart(r.carts[id])
+}
+
+func (r *fakeCartRepository) FindCart(ctx context.Context, cartID primitive.ObjectID) (*models.Cart, error) {
+	r.mu.Lock()
+	stored, ok := r.carts[cartID]
+	r.mu.Unlock()
+
+	if !ok {
+		return nil, nil
+	}
+
+	cart := cloneCart(stored)
+	if r.afterFind != nil {
+		r.afterFind()
+	}
+	return &cart, nil
+}
+
+func (r *fakeCartRepository) GetCartByTeacherStudent(ctx context.Context, teacherID string, studentID string) (*models.Cart, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, cart := range r.carts {
+		if cart.TeacherID == teacherID && cart.StudentID == studentID && !cart.Named {
+			found := cloneCart(cart)
+			return &found, nil
+		}
+	}
+
+	created := r.put(models.Cart{TeacherID: teacherID, StudentID: studentID, Items: []models.CartItem{}})
+	return &created, nil
+}
+
+func (r *fakeCartRepository) GetCartByID(ctx context.Context, teacherID string, cartID primitive.ObjectID) (*models.Cart, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.carts[cartID]
+	if !ok || stored.TeacherID != teacherID {
+		return nil, nil
+	}
+	cart := cloneCart(stored)
+	return &cart, nil
+}
+
+func (r *fakeCartRepository) GetNamedCarts(ctx context.Context, teacherID string, studentID string) ([]models.Cart, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var carts []models.Cart
+	for _, cart := range r.carts {
+		if cart.TeacherID == teacherID && cart.StudentID == studentID && cart.Named {
+			carts = append(carts, cloneCart(cart))
+		}
+	}
+	return carts, nil
+}
+
+// GetCartByTeacher - One entry per student with a default cart, keyed by student like the grouped query.
+func (r *fakeCartRepository) GetCartByTeacher(ctx context.Context, teacherID string) ([]bson.M, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	carts := []bson.M{}
+	for _, cart := range r.carts {
+		if cart.TeacherID == teacherID && !cart.Named {
+			carts = append(carts, bson.M{"_id": cart.StudentID, "items": cloneCart(cart).Items})
+		}
+	}
+	return carts, nil
+}
+
+func (r *fakeCartRepository) GetCartsByTeacher(ctx context.Context, teacherID string) ([]models.Cart, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var carts []models.Cart
+	for _, cart := range r.carts {
+		if cart.TeacherID == teacherID && !cart.Named {
+			carts = append(carts, cloneCart(cart))
+		}
+	}
+	return carts, nil
+}
+
+func (r *fakeCartRepository) UpdateCart(ctx context.Context, cart *models.Cart) error {
+	return r.write(cart)
+}
+
+func (r *fakeCartRepository) UpdateCartStatus(ctx context.Context, cart *models.Cart) error {
+	return r.write(cart)
+}
+
+func (r *fakeCartRepository) DeleteCart(ctx context.Context, cart *models.Cart) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err, ok := r.deleteErrs[cart.ID]; ok {
+		delete(r.deleteErrs, cart.ID)
+		return err
+	}
+
+	stored, ok := r.carts[cart.ID]
+	if !ok || !stored.UpdateAt.Equal(cart.UpdateAt) {
+		return models.NewServiceError(http.StatusConflict, models.ErrCartModified, "cart was changed by another request; reload it and try again", nil)
+	}
+
+	delete(r.carts, cart.ID)
+	return nil
+}
+
+func (r *fakeCartRepository) write(cart *models.Cart) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err, ok := r.updateErrs[cart.ID]; ok {
+		delete(r.updateErrs, cart.ID)
+		return err
+	}
+
+	stored, ok := r.carts[cart.ID]
+	if !ok || !stored.UpdateAt.Equal(cart.UpdateAt) {
+		return models.NewServiceError(http.StatusConflict, models.ErrCartModified, "cart was changed by another request; reload it and try again", nil)
+	}
+
+	saved := r.put(*cart)
+	cart.UpdateAt = saved.UpdateAt
+	return nil
+}
+
+func cloneCart(cart models.Cart) models.Cart {
+	cart.Items = append([]models.CartItem(nil), cart.Items...)
+	cart.SavedItems = append([]models.CartItem(nil), cart.SavedItems...)
+	if cart.Approval != nil {
+		approval := *cart.Approval
+		cart.Approval = &approval
+	}
+	return cart
+}
+
+// fakeBudgetRepository - Serves GetActiveBudgets from a fixed list; every budget is taken as active.
+type fakeBudgetRepository struct {
+	repository.BudgetRepository
+
+	budgets []models.Budget
+}
+
+func (r *fakeBudgetRepository) GetActiveBudgets(ctx context.Context, scope string, scopeIDs []string, at time.Time) ([]models.Budget, error) {
+	var active []models.Budget
+	for _, budget := range r.budgets {
+		if budget.Scope != scope {
+			continue
+		}
+		for _, id := range scopeIDs {
+			if budget.ScopeID == id {
+				active = append(active, budget)
+				break
+			}
+		}
+	}
+	return active, nil
+}
+
+// fakeCartMemberRepository - Serves the membership lookups from a fixed list of grants.
+type fakeCartMemberRepository struct {
+	repository.CartMemberRepository
+
+	grants []models.CartMember
+}
+
+func (r *fakeCartMemberRepository) GetMemberships(ctx context.Context, ownerID string, memberID string) ([]models.CartMember, error) {
+	var grants []models.CartMember
+	for _, grant := range r.grants {
+		if grant.OwnerID == ownerID && grant.MemberID == memberID {
+			grants = append(grants, grant)
+		}
+	}
+	return grants, nil
+}
+
+func (r *fakeCartMemberRepository) GetMembershipsByMember(ctx context.Context, memberID string) ([]models.CartMember, error) {
+	var grants []models.CartMember
+	for _, grant := range r.grants {
+		if grant.MemberID == memberID {
+			grants = append(grants, grant)
+		}
+	}
+	return grants, nil
+}
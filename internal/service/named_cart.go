@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"store/internal/models"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// resolveCart - The cart an item request works on: the named cart with cartID, or the student's
+// default cart when cartID is empty.
+func (s *cartService) resolveCart(ctx context.Context, teacherID string, studentID string, cartID string) (*models.Cart, error) {
+	if cartID == "" {
+		cart, err := s.repoCart.GetCartByTeacherStudent(ctx, teacherID, studentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cart: %w", err)
+		}
+		return cart, nil
+	}
+
+	cart, err := s.getCart(ctx, teacherID, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cart.StudentID != studentID {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidRequest, fmt.Sprintf("cart %s does not belong to student %s", cartID, studentID), nil)
+	}
+
+	return cart, nil
+}
+
+// getCart - Loads one of the teacher's carts by ID.
+func (s *cartService) getCart(ctx context.Context, teacherID string, cartID string) (*models.Cart, error) {
+	id, err := primitive.ObjectIDFromHex(cartID)
+	if err != nil {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidRequest, "invalid cart ID", nil)
+	}
+
+	cart, err := s.repoCart.GetCartByID(ctx, teacherID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	if cart == nil {
+		return nil, models.NewServiceError(http.StatusNotFound, models.ErrCartNotFound, "cart not found", nil)
+	}
+
+	return cart, nil
+}
+
+// getNamedCart - Like getCart, but refuses the default cart.
+func (s *cartService) getNamedCart(ctx context.Context, teacherID string, cartID string) (*models.Cart, error) {
+	cart, err := s.getCart(ctx, teacherID, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cart.Named {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidOperation, "the default cart is managed through the item endpoints", nil)
+	}
+
+	return cart, nil
+}
+
+// checkoutGroup - The carts that are ordered together with cart: all of the teacher's default
+// carts, or the named cart on its own. The given cart stands in for its stored copy, so changes
+// not yet saved are counted.
+func (s *cartService) checkoutGroup(ctx context.Context, cart *models.Cart) ([]models.Cart, error) {
+	if cart.Named {
+		return []models.Cart{*cart}, nil
+	}
+
+	carts, err := s.repoCart.GetCartsByTeacher(ctx, cart.TeacherID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get carts: %w", err)
+	}
+
+	for i := range carts {
+		if carts[i].ID == cart.ID {
+			carts[i] = *cart
+			return carts, nil
+		}
+	}
+
+	return append(carts, *cart), nil
+}
+
+// ensureCartNameFree - Names are unique among a student's named carts, ignoring case.
+func (s *cartService) ensureCartNameFree(ctx context.Context, teacherID string, studentID string, name string, exceptID primitive.ObjectID) error {
+	carts, err := s.repoCart.GetNamedCarts(ctx, teacherID, studentID)
+	if err != nil {
+		return fmt.Errorf("failed to get carts: %w", err)
+	}
+
+	for _, cart := range carts {
+		if cart.ID != exceptID && strings.EqualFold(cart.Name, name) {
+			return models.NewServiceError(http.StatusConflict, models.ErrCartNameTaken, fmt.Sprintf("a cart named %q already exists for this student", name), nil)
+		}
+	}
+
+	return nil
+}
+
+func (s *cartService) GetNamedCarts(ctx context.Context, teacherID string, ownerID string, studentID string) ([]models.Cart, error) {
+
+	owner, err := s.cartOwner(ctx, teacherID, ownerID, studentID, models.CartPermissionView)
+	if err != nil {
+		return nil, err
+	}
+
+	carts, err := s.repoCart.GetNamedCarts(ctx, owner, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.withNamedCartBudgets(ctx, owner, carts); err != nil {
+		return nil, err
+	}
+
+	return carts, nil
+}
+
+func (s *cartService) CreateNamedCart(ctx context.Context, req *models.CreateCartRequest) (*models.Cart, error) {
+
+	teacherID, err := s.studentCartOwner(ctx, req.TeacherID, req.OwnerID, req.StudentID, models.CartPermissionEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+
+	if err := s.ensureCartNameFree(ctx, teacherID, req.StudentID, name, primitive.NilObjectID); err != nil {
+		return nil, err
+	}
+
+	cart := &models.Cart{
+		TeacherID: teacherID,
+		StudentID: req.StudentID,
+		Name:      name,
+		Named:     true,
+	}
+
+	if err := s.repoCart.CreateCart(ctx, cart); err != nil {
+		return nil, fmt.Errorf("failed to create cart: %w", err)
+	}
+
+	return cart, nil
+}
+
+func (s *cartService) RenameCart(ctx context.Context, cartID string, req *models.RenameCartRequest) (*models.Cart, error) {
+
+	cart, err := s.getNamedCart(ctx, ownerOrSelf(req.TeacherID, req.OwnerID), cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.cartOwner(ctx, req.TeacherID, req.OwnerID, cart.StudentID, models.CartPermissionEdit); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+
+	if err := s.ensureCartNameFree(ctx, cart.TeacherID, cart.StudentID, name, cart.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repoCart.RenameCart(ctx, cart, name); err != nil {
+		return nil, fmt.Errorf("failed to rename cart: %w", err)
+	}
+
+	return cart, nil
+}
+
+// DeleteCart - Deletes a named cart. Lines still in it are recorded as removed.
+func (s *cartService) DeleteCart(ctx context.Context, teacherID string, ownerID string, cartID string) error {
+
+	cart, err := s.getNamedCart(ctx, ownerOrSelf(teacherID, ownerID), cartID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.cartOwner(ctx, teacherID, ownerID, cart.StudentID, models.CartPermissionEdit); err != nil {
+		return err
+	}
+
+	if err := s.repoCart.DeleteCart(ctx, cart); err != nil {
+		return fmt.Errorf("failed to delete cart: %w", err)
+	}
+
+	for _, item := range cart.Items {
+		if err := s.repoHistory.AddCartHistory(ctx, cart.TeacherID, cart.StudentID, item, "remove", item.Quantity); err != nil {
+			return fmt.Errorf("unable to add cart history: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CheckOutNamedCart - Orders a single named cart and empties it. The cart itself is kept.
+func (s *cartService) CheckOutNamedCart(ctx context.Context, cartID string, req *models.CheckOutCartRequest) error {
+
+	cart, err := s.getNamedCart(ctx, ownerOrSelf(req.TeacherID, req.OwnerID), cartID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.cartOwner(ctx, req.TeacherID, req.OwnerID, cart.StudentID, models.CartPermissionCheckout); err != nil {
+		return err
+	}
+
+	if len(cart.Items) == 0 {
+		return models.NewServiceError(http.StatusBadRequest, models.ErrInvalidOperation, "cart is empty", nil)
+	}
+
+	// Checkout reprices the lines in the slice, so it holds the cart as last saved.
+	ordered := []models.Cart{*cart}
+
+	if err := s.checkOut(ctx, cart.TeacherID, req, ordered, true); err != nil {
+		return err
+	}
+
+	if err := s.repoHistory.AddOrderHistory(ctx, ordered[0]); err != nil {
+		return fmt.Errorf("order created, but failed to add cart history: %v", err)
+	}
+
+	if err := s.markOrdered(ctx, ordered); err != nil {
+		return fmt.Errorf("order created, but failed to update cart status: %v", err)
+	}
+
+	if err := s.repoCart.EmptyCart(ctx, &ordered[0]); err != nil {
+		return fmt.Errorf("order created, but failed to clear cart: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"store/internal/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestResolveCart(t *testing.T) {
+	repo := newFakeCartRepository()
+	defaultCart := repo.put(models.Cart{TeacherID: "t1", StudentID: "s1"})
+	trip := repo.put(models.Cart{TeacherID: "t1", StudentID: "s1", Name: "Field trip", Named: true})
+	other := repo.put(models.Cart{TeacherID: "t1", StudentID: "s2", Name: "Term 1", Named: true})
+	foreign := repo.put(models.Cart{TeacherID: "t2", StudentID: "s1", Name: "Term 1", Named: true})
+	s := &cartService{repoCart: repo}
+
+	tests := []struct {
+		name     string
+		cartID   string
+		want     primitive.ObjectID
+		wantCode string
+	}{
+		{name: "default cart", want: defaultCart.ID},
+		{name: "named cart", cartID: trip.ID.Hex(), want: trip.ID},
+		{name: "cart of another student", cartID: other.ID.Hex(), wantCode: models.ErrInvalidRequest},
+		{name: "cart of another teacher", cartID: foreign.ID.Hex(), wantCode: models.ErrCartNotFound},
+		{name: "unknown cart", cartID: primitive.NewObjectID().Hex(), wantCode: models.ErrCartNotFound},
+		{name: "malformed ID", cartID: "trip", wantCode: models.ErrInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart, err := s.resolveCart(context.Background(), "t1", "s1", tt.cartID)
+
+			if tt.wantCode != "" {
+				var serviceErr *models.ServiceError
+				if !errors.As(err, &serviceErr) || serviceErr.ErrorCode != tt.wantCode {
+					t.Errorf("resolveCart() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil || cart.ID != tt.want {
+				t.Errorf("resolveCart() = %v, %v, want cart %s", cart, err, tt.want.Hex())
+			}
+		})
+	}
+}
+
+func TestEnsureCartNameFree(t *testing.T) {
+	repo := newFakeCartRepository()
+	repo.put(models.Cart{TeacherID: "t1", StudentID: "s1"})
+	trip := repo.put(models.Cart{TeacherID: "t1", StudentID: "s1", Name: "Field trip", Named: true})
+	repo.put(models.Cart{TeacherID: "t1", StudentID: "s2", Name: "Term 1", Named: true})
+	s := &cartService{repoCart: repo}
+
+	tests := []struct {
+		name     string
+		cartName string
+		exceptID primitive.ObjectID
+		wantErr  bool
+	}{
+		{name: "free name", cartName: "Term 1"},
+		{name: "taken name", cartName: "Field trip", wantErr: true},
+		{name: "taken name in another case", cartName: "FIELD TRIP", wantErr: true},
+		{name: "cart keeping its own name", cartName: "field trip", exceptID: trip.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ensureCartNameFree(context.Background(), "t1", "s1", tt.cartName, tt.exceptID)
+
+			var serviceErr *models.ServiceError
+			if taken := errors.As(err, &serviceErr) && serviceErr.ErrorCode == models.ErrCartNameTaken; taken != tt.wantErr {
+				t.Errorf("ensureCartNameFree(%q) error = %v, want taken %v", tt.cartName, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckoutGroup(t *testing.T) {
+	pen := models.CartItem{ProductID: primitive.NewObjectID(), Quantity: 1}
+
+	repo := newFakeCartRepository()
+	repo.put(models.Cart{TeacherID: "t1", StudentID: "s1"})
+	second := repo.put(models.Cart{TeacherID: "t1", StudentID: "s2"})
+	trip := repo.put(models.Cart{TeacherID: "t1", StudentID: "s1", Name: "Field trip", Named: true})
+	repo.put(models.Cart{TeacherID: "t2", StudentID: "s3"})
+	s := &cartService{repoCart: repo}
+
+	tests := []struct {
+		name string
+		cart models.Cart
+		want []string
+	}{
+		{name: "named cart on its own", cart: trip, want: []string{"s1:Field trip"}},
+		{name: "every default cart of the teacher", cart: second, want: []string{"s1:", "s2:"}},
+		{name: "unsaved default cart", cart: models.Cart{ID: primitive.NewObjectID(), TeacherID: "t1", StudentID: "s4"}, want: []string{"s1:", "s2:", "s4:"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := tt.cart
+			cart.Items = []models.CartItem{pen}
+
+			carts, err := s.checkoutGroup(context.Background(), &cart)
+			if err != nil {
+				t.Fatalf("checkoutGroup() error = %v", err)
+			}
+
+			var got []string
+			for _, grouped := range carts {
+				got = append(got, grouped.StudentID+":"+grouped.Name)
+				if grouped.ID == cart.ID && len(grouped.Items) != 1 {
+					t.Errorf("the stored copy of the cart was used instead of the unsaved one")
+				}
+			}
+			sort.Strings(got)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("checkoutGroup() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("checkoutGroup() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
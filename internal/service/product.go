@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"store/internal/models"
+	"store/pkg/constants"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// productSnapshot - The parts of a product-service payload that cart lines are built from.
+type productSnapshot struct {
+	ID           primitive.ObjectID
+	Name         string
+	TopicName    string
+	CategoryName string
+	ImageURL     string
+	PriceStore   float64
+	PriceService float64
+	// PurchaseLimit is the most a teacher may buy of the product; zero means unlimited.
+	PurchaseLimit int
+	// Stock and PromotionStock are nil when the product does not track them.
+	Stock          *int
+	PromotionStock *int
+	Promotion      *productPromotion
+	UsageConfig    *models.UsageConfig
+	Variations     []productVariation
+}
+
+// productPromotion - An active product discount. A percentage off applies to both prices;
+// otherwise a fixed discount price replaces the store price.
+type productPromotion struct {
+	Name          string
+	PercentOff    float64
+	DiscountPrice float64
+}
+
+// productVariation - One selectable option of a product variation, e.g. Size "M".
+type productVariation struct {
+	VariationName string
+	Option        string
+	Price         *float64
+	Stock         *int
+	Image         string
+}
+
+// GetProduct - Fetches a product and decodes it into a snapshot.
+func (c *callAPI) GetProduct(ctx context.Context, productID primitive.ObjectID) (*productSnapshot, error) {
+	productRes := c.GetProductByID(ctx, productID.Hex())
+	if productRes == nil {
+		return nil, models.NewServiceError(http.StatusNotFound, models.ErrProductNotFound, "product not found", nil)
+	}
+
+	data, ok := productRes["data"].(map[string]interface{})
+	if !ok {
+		return nil, models.NewServiceError(http.StatusNotFound, models.ErrProductNotFound, "product not found", nil)
+	}
+
+	return parseProduct(productID, data), nil
+}
+
+func parseProduct(productID primitive.ObjectID, product map[string]interface{}) *productSnapshot {
+	snapshot := &productSnapshot{
+		ID:           productID,
+		Name:         stringValue(product[constants.ProductName]),
+		ImageURL:     stringValue(product[constants.CoverImage]),
+		PriceStore:   floatValue(product["original_price_store"]),
+		PriceService: floatValue(product["original_price_service"]),
+	}
+	snapshot.PurchaseLimit = int(floatValue(product[constants.PurchaseLimit]))
+	snapshot.Stock = intPointer(product[constants.Stock])
+	snapshot.PromotionStock = intPointer(product[constants.PromotionStock])
+
+	if topic, ok := product["topic"].(map[string]interface{}); ok {
+		snapshot.TopicName = stringValue(topic["topic_name"])
+	}
+
+	if category, ok := product["category"].(map[string]interface{}); ok {
+		snapshot.CategoryName = stringValue(category["category_name"])
+	}
+
+	snapshot.Promotion = parsePromotion(product)
+	snapshot.UsageConfig = parseUsageConfig(product[constants.UsageConfig])
+	snapshot.Variations = parseVariations(product[constants.Variations])
+
+	return snapshot
+}
+
+// cartProducts - Fetches every product that appears in the carts, once each.
+func (s *cartService) cartProducts(ctx context.Context, carts []models.Cart) (map[primitive.ObjectID]*productSnapshot, error) {
+	products := map[primitive.ObjectID]*productSnapshot{}
+	for _, cart := range carts {
+		for _, item := range cart.Items {
+			if _, ok := products[item.ProductID]; ok {
+				continue
+			}
+			product, err := s.productAPI.GetProduct(ctx, item.ProductID)
+			if err != nil {
+				return nil, err
+			}
+			products[item.ProductID] = product
+		}
+	}
+	return products, nil
+}
+
+// refreshCartPrices - Reprices every line from the current products before an order, so that
+// a promotion which has ended since the line was added is not charged. Carts whose prices
+// changed are saved.
+func (s *cartService) refreshCartPrices(ctx context.Context, carts []models.Cart, products map[primitive.ObjectID]*productSnapshot) error {
+	for c := range carts {
+		cart := &carts[c]
+
+		changed, err := repriceLines(cart.Items, products)
+		if err != nil {
+			return err
+		}
+
+		if !changed {
+			continue
+		}
+
+		cart.CalculateTotals()
+		if err := s.repoCart.UpdateCart(ctx, cart); err != nil {
+			return fmt.Errorf("failed to save cart prices: %w", err)
+		}
+	}
+	return nil
+}
+
+// repriceLines - Reprices the lines in place from the current products and reports whether any
+// price changed.
+func repriceLines(items []models.CartItem, products map[primitive.ObjectID]*productSnapshot) (bool, error) {
+	changed := false
+	for i := range items {
+		item := &items[i]
+
+		latest, err := products[item.ProductID].cartItem(item.Quantity, item.Variation)
+		if err != nil {
+			return false, err
+		}
+
+		if item.RefreshPrices(*latest) {
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// parseUsageConfig - Reads the usage config object, if the product has one.
+func parseUsageConfig(raw interface{}) *models.UsageConfig {
+	usage, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	return &models.UsageConfig{
+		NumberOfUses:     int(floatValue(usage[constants.NumberOfUses])),
+		MinimumUsageTime: int(floatValue(usage[constants.MinimumUsageTime])),
+		MaximumUsageTime: int(floatValue(usage[constants.MaximumUsageTime])),
+	}
+}
+
+// parsePromotion - Reads the discount fields. Disabled or empty discounts yield no promotion.
+func parsePromotion(product map[string]interface{}) *productPromotion {
+	if enabled, _ := product[constants.DiscountEnabled].(bool); !enabled {
+		return nil
+	}
+
+	promotion := &productPromotion{
+		Name:          stringValue(product[constants.DiscountPromotion]),
+		PercentOff:    floatValue(product[constants.DiscountOff]),
+		DiscountPrice: floatValue(product[constants.DiscountPrice]),
+	}
+
+	if promotion.PercentOff <= 0 && promotion.DiscountPrice <= 0 {
+		return nil
+	}
+	if promotion.PercentOff > 100 {
+		promotion.PercentOff = 100
+	}
+
+	return promotion
+}
+
+// apply - Returns the promotional store and service prices, and whether either was lowered.
+// The fixed discount price only replaces the product's own store price, not a variation price.
+func (p *productPromotion) apply(priceStore float64, priceService float64, variationPriced bool) (float64, float64, bool) {
+	if p == nil {
+		return priceStore, priceService, false
+	}
+
+	if p.PercentOff > 0 {
+		factor := 1 - p.PercentOff/100
+		return models.RoundPrice(priceStore * factor), models.RoundPrice(priceService * factor), true
+	}
+
+	if !variationPriced && p.DiscountPrice < priceStore {
+		return p.DiscountPrice, priceService, true
+	}
+
+	return priceStore, priceService, false
+}
+
+// parseVariations - Accepts both a flat list of {variation_name, option, price, stock, image}
+// entries and a nested list where each variation carries its options as an array.
+func parseVariations(raw interface{}) []productVariation {
+	entries, ok := raw.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var variations []productVariation
+	for _, rawEntry := range entries {
+		entry, ok := rawEntry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name := stringValue(entry[constants.VariationName])
+
+		options, nested := entry[constants.Option].([]interface{})
+		if !nested {
+			options, nested = entry["options"].([]interface{})
+		}
+
+		if !nested {
+			variations = append(variations, newProductVariation(name, entry))
+			continue
+		}
+
+		for _, rawOption := range options {
+			switch option := rawOption.(type) {
+			case string:
+				variations = append(variations, productVariation{VariationName: name, Option: option})
+			case map[string]interface{}:
+				variations = append(variations, newProductVariation(name, option))
+			}
+		}
+	}
+
+	return variations
+}
+
+func newProductVariation(name string, option map[string]interface{}) productVariation {
+	variation := productVariation{
+		VariationName: name,
+		Option:        stringValue(option[constants.Option]),
+		Image:         stringValue(option[constants.Image]),
+	}
+	if variation.Option == "" {
+		variation.Option = stringValue(option["name"])
+	}
+
+	if price, ok := option[constants.Price].(float64); ok {
+		variation.Price = &price
+	}
+
+	variation.Stock = intPointer(option[constants.Stock])
+
+	return variation
+}
+
+// findVariation - Looks up the selected option among the product's variations.
+func (p *productSnapshot) findVariation(selection *models.VariationSelection) (*productVariation, bool) {
+	for i := range p.Variations {
+		if p.Variations[i].VariationName == selection.VariationName && p.Variations[i].Option == selection.Option {
+			return &p.Variations[i], true
+		}
+	}
+	return nil, false
+}
+
+// cartItem - Builds a cart line for the product. A product with variations needs one of them selected,
+// and the selection must exist on the product. A variation price replaces the store price only: product-service
+// prices variations for the store, while the service price is set per product and applies to every option.
+// The variation image replaces the cover image when it is set. The line keeps the original prices and
+// carries the promotional ones as its effective prices.
+func (p *productSnapshot) cartItem(quantity int, selection *models.VariationSelection) (*models.CartItem, error) {
+	if selection == nil && len(p.Variations) > 0 {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidVariation, "product has variations, select one of them", nil)
+	}
+
+	item := &models.CartItem{
+		ProductID:            p.ID,
+		Variation:            selection,
+		Quantity:             quantity,
+		ProductName:          p.Name,
+		TopicName:            p.TopicName,
+		CategoryName:         p.CategoryName,
+		OriginalPriceStore:   p.PriceStore,
+		OriginalPriceService: p.PriceService,
+		ImageURL:             p.ImageURL,
+		UsageConfig:          p.UsageConfig,
+	}
+
+	variationPriced := false
+	if selection != nil {
+		variation, ok := p.findVariation(selection)
+		if !ok {
+			return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidVariation, fmt.Sprintf("product has no variation %s: %s", selection.VariationName, selection.Option), nil)
+		}
+
+		if variation.Price != nil {
+			item.OriginalPriceStore = *variation.Price
+			variationPriced = true
+		}
+		if variation.Image != "" {
+			item.ImageURL = variation.Image
+		}
+	}
+
+	item.PriceStore, item.PriceService, item.PromotionApplied = p.Promotion.apply(item.OriginalPriceStore, item.OriginalPriceService, variationPriced)
+	if item.PromotionApplied {
+		item.PromotionName = p.Promotion.Name
+	}
+
+	return item, nil
+}
+
+func stringValue(raw interface{}) string {
+	value, _ := raw.(string)
+	return value
+}
+
+func intPointer(raw interface{}) *int {
+	switch value := raw.(type) {
+	case float64:
+		quantity := int(value)
+		return &quantity
+	case int:
+		return &value
+	}
+	return nil
+}
+
+func floatValue(raw interface{}) float64 {
+	switch value := raw.(type) {
+	case float64:
+		return value
+	case int:
+		return float64(value)
+	}
+	return 0
+}
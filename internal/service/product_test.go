@@ -0,0 +1,194 @@
+package service
+
+import (
+	"errors"
+	"store/internal/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPromotionApply(t *testing.T) {
+	tests := []struct {
+		name            string
+		promotion       *productPromotion
+		variationPriced bool
+		wantStore       float64
+		wantService     float64
+		wantApplied     bool
+	}{
+		{name: "no promotion", wantStore: 10, wantService: 4},
+		{name: "percent off both prices", promotion: &productPromotion{PercentOff: 15}, wantStore: 8.5, wantService: 3.4, wantApplied: true},
+		{name: "percent off is rounded", promotion: &productPromotion{PercentOff: 33}, wantStore: 6.7, wantService: 2.68, wantApplied: true},
+		{name: "percent off a variation price", promotion: &productPromotion{PercentOff: 50}, variationPriced: true, wantStore: 5, wantService: 2, wantApplied: true},
+		{name: "discount price", promotion: &productPromotion{DiscountPrice: 7.5}, wantStore: 7.5, wantService: 4, wantApplied: true},
+		{name: "discount price above the price", promotion: &productPromotion{DiscountPrice: 12}, wantStore: 10, wantService: 4},
+		{name: "discount price skips variation prices", promotion: &productPromotion{DiscountPrice: 7.5}, variationPriced: true, wantStore: 10, wantService: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, service, applied := tt.promotion.apply(10, 4, tt.variationPriced)
+			if store != tt.wantStore || service != tt.wantService || applied != tt.wantApplied {
+				t.Errorf("apply() = %v, %v, %v, want %v, %v, %v", store, service, applied, tt.wantStore, tt.wantService, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestParsePromotion(t *testing.T) {
+	tests := []struct {
+		name    string
+		product map[string]interface{}
+		want    *productPromotion
+	}{
+		{
+			name:    "disabled",
+			product: map[string]interface{}{"discount_enabled": false, "discount_off": 20.0},
+		},
+		{
+			name:    "enabled without a discount",
+			product: map[string]interface{}{"discount_enabled": true},
+		},
+		{
+			name:    "percent off",
+			product: map[string]interface{}{"discount_enabled": true, "discount_off": 20.0, "discount_promotion": "Spring"},
+			want:    &productPromotion{Name: "Spring", PercentOff: 20},
+		},
+		{
+			name:    "percent off is capped",
+			product: map[string]interface{}{"discount_enabled": true, "discount_off": 150.0},
+			want:    &productPromotion{PercentOff: 100},
+		},
+		{
+			name:    "discount price",
+			product: map[string]interface{}{"discount_enabled": true, "discount_price": 5.0},
+			want:    &productPromotion{DiscountPrice: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parsePromotion(tt.product)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("parsePromotion() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProductCartItem(t *testing.T) {
+	price := 12.0
+	product := &productSnapshot{
+		ID:           primitive.NewObjectID(),
+		Name:         "T-shirt",
+		ImageURL:     "cover.png",
+		PriceStore:   10,
+		PriceService: 4,
+		Promotion:    &productPromotion{Name: "Spring", PercentOff: 25},
+		Variations: []productVariation{
+			{VariationName: "Size", Option: "S"},
+			{VariationName: "Size", Option: "XL", Price: &price, Image: "xl.png"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		selection *models.VariationSelection
+		want      models.CartItem
+		wantErr   string
+	}{
+		{
+			name:    "no variation selected",
+			wantErr: models.ErrInvalidVariation,
+		},
+		{
+			name:      "variation without its own price",
+			selection: &models.VariationSelection{VariationName: "Size", Option: "S"},
+			want:      models.CartItem{PriceStore: 7.5, PriceService: 3, OriginalPriceStore: 10, OriginalPriceService: 4, ImageURL: "cover.png"},
+		},
+		{
+			name:      "variation price applies to the store price only",
+			selection: &models.VariationSelection{VariationName: "Size", Option: "XL"},
+			want:      models.CartItem{PriceStore: 9, PriceService: 3, OriginalPriceStore: 12, OriginalPriceService: 4, ImageURL: "xl.png"},
+		},
+		{
+			name:      "unknown variation",
+			selection: &models.VariationSelection{VariationName: "Size", Option: "M"},
+			wantErr:   models.ErrInvalidVariation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := product.cartItem(2, tt.selection)
+			if tt.wantErr != "" {
+				var serviceErr *models.ServiceError
+				if !errors.As(err, &serviceErr) || serviceErr.ErrorCode != tt.wantErr {
+					t.Fatalf("cartItem() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("cartItem: %v", err)
+			}
+
+			if item.Quantity != 2 || item.ProductName != "T-shirt" || !item.PromotionApplied || item.PromotionName != "Spring" {
+				t.Errorf("cartItem() = %+v", item)
+			}
+			if item.PriceStore != tt.want.PriceStore || item.PriceService != tt.want.PriceService ||
+				item.OriginalPriceStore != tt.want.OriginalPriceStore || item.OriginalPriceService != tt.want.OriginalPriceService ||
+				item.ImageURL != tt.want.ImageURL {
+				t.Errorf("cartItem() prices = %v/%v (original %v/%v), image %q, want %v/%v (original %v/%v), image %q",
+					item.PriceStore, item.PriceService, item.OriginalPriceStore, item.OriginalPriceService, item.ImageURL,
+					tt.want.PriceStore, tt.want.PriceService, tt.want.OriginalPriceStore, tt.want.OriginalPriceService, tt.want.ImageURL)
+			}
+		})
+	}
+}
+
+func TestProductCartItemWithoutVariations(t *testing.T) {
+	product := &productSnapshot{ID: primitive.NewObjectID(), Name: "Pen", ImageURL: "pen.png", PriceStore: 2, PriceService: 0.5}
+
+	item, err := product.cartItem(3, nil)
+	if err != nil {
+		t.Fatalf("cartItem: %v", err)
+	}
+	if item.Variation != nil || item.Quantity != 3 || item.PriceStore != 2 || item.PriceService != 0.5 || item.PromotionApplied {
+		t.Errorf("cartItem() = %+v", item)
+	}
+
+	if _, err := product.cartItem(1, &models.VariationSelection{VariationName: "Color", Option: "Blue"}); err == nil {
+		t.Error("cartItem() accepted a variation the product does not have")
+	}
+}
+
+func TestParseVariations(t *testing.T) {
+	flat := []interface{}{
+		map[string]interface{}{"variation_name": "Size", "option": "S", "price": 10.0, "stock": 3.0},
+		map[string]interface{}{"variation_name": "Size", "option": "M"},
+	}
+	nested := []interface{}{
+		map[string]interface{}{"variation_name": "Size", "options": []interface{}{
+			map[string]interface{}{"option": "S", "price": 10.0, "stock": 3.0},
+			"M",
+		}},
+	}
+
+	for name, raw := range map[string]interface{}{"flat": flat, "nested": nested} {
+		t.Run(name, func(t *testing.T) {
+			variations := parseVariations(raw)
+			if len(variations) != 2 {
+				t.Fatalf("parseVariations() returned %d variations, want 2: %+v", len(variations), variations)
+			}
+
+			small := variations[0]
+			if small.VariationName != "Size" || small.Option != "S" || small.Price == nil || *small.Price != 10 || small.Stock == nil || *small.Stock != 3 {
+				t.Errorf("first variation = %+v", small)
+			}
+			if medium := variations[1]; medium.VariationName != "Size" || medium.Option != "M" || medium.Price != nil {
+				t.Errorf("second variation = %+v", medium)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"store/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// purchaseLimitPerTeacher - Scope that counts purchase limits across all of a teacher's carts.
+const purchaseLimitPerTeacher = "teacher"
+
+// purchaseLimitStudent - The student a purchase limit is counted for, or "" when it spans the teacher's carts.
+func (s *cartService) purchaseLimitStudent(studentID string) string {
+	if s.cartConfig.PurchaseLimitScope == purchaseLimitPerTeacher {
+		return ""
+	}
+	return studentID
+}
+
+// quantityInCarts - Sums the quantity of a product over every variation line in the given carts,
+// counting only the student's cart unless studentID is empty.
+func quantityInCarts(carts []models.Cart, studentID string, productID primitive.ObjectID) int {
+	quantity := 0
+	for _, cart := range carts {
+		if studentID != "" && cart.StudentID != studentID {
+			continue
+		}
+		for _, item := range cart.Items {
+			if item.ProductID == productID {
+				quantity += item.Quantity
+			}
+		}
+	}
+	return quantity
+}
+
+// ensureWithinPurchaseLimit - Rejects adding requested units of the product to cart when past orders
+// and the units already in the carts ordered with it leave too little of its purchase limit.
+func (s *cartService) ensureWithinPurchaseLimit(ctx context.Context, cart *models.Cart, product *productSnapshot, requested int) error {
+	if product.PurchaseLimit <= 0 {
+		return nil
+	}
+
+	carts, err := s.checkoutGroup(ctx, cart)
+	if err != nil {
+		return err
+	}
+
+	return s.checkPurchaseLimit(ctx, cart, carts, product, requested)
+}
+
+// checkPurchaseLimit - The check behind ensureWithinPurchaseLimit, against an already loaded
+// checkout group.
+func (s *cartService) checkPurchaseLimit(ctx context.Context, cart *models.Cart, carts []models.Cart, product *productSnapshot, requested int) error {
+	if product.PurchaseLimit <= 0 {
+		return nil
+	}
+
+	detail, err := s.purchaseLimitUsage(ctx, cart.TeacherID, s.purchaseLimitStudent(cart.StudentID), product, carts)
+	if err != nil {
+		return err
+	}
+
+	detail.Requested = requested
+	if requested <= detail.Remaining {
+		return nil
+	}
+
+	return models.NewServiceError(http.StatusConflict, models.ErrPurchaseLimit, fmt.Sprintf("purchase limit of %d reached for %s, %d more allowed", product.PurchaseLimit, product.Name, detail.Remaining), []models.PurchaseLimitDetail{*detail})
+}
+
+// ensureCartsWithinPurchaseLimits - Re-checks every product in the teacher's carts before checkout.
+func (s *cartService) ensureCartsWithinPurchaseLimits(ctx context.Context, teacherID string, carts []models.Cart, products map[primitive.ObjectID]*productSnapshot) error {
+	checked := map[string]bool{}
+	var violations []models.PurchaseLimitDetail
+
+	for _, cart := range carts {
+		studentID := s.purchaseLimitStudent(cart.StudentID)
+
+		for _, item := range cart.Items {
+			key := studentID + "|" + item.ProductID.Hex()
+			if checked[key] {
+				continue
+			}
+			checked[key] = true
+
+			product := products[item.ProductID]
+			if product.PurchaseLimit <= 0 {
+				continue
+			}
+
+			detail, err := s.purchaseLimitUsage(ctx, teacherID, studentID, product, carts)
+			if err != nil {
+				return err
+			}
+
+			// At checkout the units in the cart are the ones being bought.
+			detail.Requested = detail.InCart
+			detail.Remaining = max(product.PurchaseLimit-detail.Ordered, 0)
+			if detail.Requested > detail.Remaining {
+				violations = append(violations, *detail)
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return models.NewServiceError(http.StatusConflict, models.ErrPurchaseLimit, "cart exceeds product purchase limits", violations)
+	}
+
+	return nil
+}
+
+// purchaseLimitUsage - Counts what has been ordered and what is in the carts against the product's limit.
+func (s *cartService) purchaseLimitUsage(ctx context.Context, teacherID string, studentID string, product *productSnapshot, carts []models.Cart) (*models.PurchaseLimitDetail, error) {
+	ordered, err := s.repoHistory.CountOrderedQuantity(ctx, teacherID, studentID, product.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count ordered quantity: %w", err)
+	}
+
+	inCart := quantityInCarts(carts, studentID, product.ID)
+
+	return &models.PurchaseLimitDetail{
+		ProductID:     product.ID.Hex(),
+		ProductName:   product.Name,
+		StudentID:     studentID,
+		PurchaseLimit: product.PurchaseLimit,
+		Ordered:       ordered,
+		InCart:        inCart,
+		Remaining:     max(product.PurchaseLimit-ordered-inCart, 0),
+	}, nil
+}
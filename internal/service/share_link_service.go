@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"store/config"
+	"store/internal/models"
+	"store/internal/repository"
+	"store/pkg/auth"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ShareLinkService interface {
+	CreateShareLink(ctx context.Context, req *models.CreateShareLinkRequest) (*models.ShareLinkToken, error)
+	GetShareLinks(ctx context.Context, teacherID string, ownerID string, studentID string) ([]models.ShareLink, error)
+	RevokeShareLink(ctx context.Context, teacherID string, linkID string) error
+	GetSharedCart(ctx context.Context, token string) (*models.SharedCart, error)
+}
+
+type shareLinkService struct {
+	repo     repository.ShareLinkRepository
+	repoCart repository.CartRepository
+	members  repository.CartMemberRepository
+	students StudentDirectory
+	signer   *auth.ShareTokenSigner
+	config   config.ShareLinkConfig
+}
+
+// NewShareLinkService - A nil signer disables share links; every call then answers 503.
+func NewShareLinkService(repo repository.ShareLinkRepository, repoCart repository.CartRepository, members repository.CartMemberRepository, students StudentDirectory, signer *auth.ShareTokenSigner, cfg config.ShareLinkConfig) ShareLinkService {
+	return &shareLinkService{
+		repo:     repo,
+		repoCart: repoCart,
+		members:  members,
+		students: students,
+		signer:   signer,
+		config:   cfg,
+	}
+}
+
+func (s *shareLinkService) ensureEnabled() error {
+	if s.signer == nil {
+		return models.NewServiceError(http.StatusServiceUnavailable, models.ErrInvalidOperation, "share links are not configured", nil)
+	}
+	return nil
+}
+
+// CreateShareLink - Creates a link to a student's cart. Anyone with view access to the cart may share it.
+func (s *shareLinkService) CreateShareLink(ctx context.Context, req *models.CreateShareLinkRequest) (*models.ShareLinkToken, error) {
+
+	if err := s.ensureEnabled(); err != nil {
+		return nil, err
+	}
+
+	teacherID, err := cartOwner(ctx, s.members, req.TeacherID, req.OwnerID, req.StudentID, models.CartPermissionView)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureTeacherOfStudent(ctx, s.students, teacherID, req.StudentID); err != nil {
+		return nil, err
+	}
+
+	link := &models.ShareLink{
+		TeacherID: teacherID,
+		StudentID: req.StudentID,
+		CreatedBy: req.TeacherID,
+		ExpiresAt: time.Now().Add(s.ttl(req.ExpiresInHours)),
+	}
+
+	if req.CartID != "" {
+		cartID, err := primitive.ObjectIDFromHex(req.CartID)
+		if err != nil {
+			return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidRequest, "invalid cart ID", nil)
+		}
+
+		cart, err := s.repoCart.GetCartByID(ctx, teacherID, cartID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cart: %w", err)
+		}
+
+		if cart == nil || cart.StudentID != req.StudentID {
+			return nil, models.NewServiceError(http.StatusNotFound, models.ErrCartNotFound, "cart not found", nil)
+		}
+
+		link.CartID = &cart.ID
+	}
+
+	if err := s.repo.CreateShareLink(ctx, link); err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+
+	token, err := s.signer.Sign(link.ID.Hex(), link.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign share link: %w", err)
+	}
+
+	return &models.ShareLinkToken{ShareLink: *link, Token: token}, nil
+}
+
+// ttl - The requested lifetime, or the default one, capped at the configured maximum.
+func (s *shareLinkService) ttl(hours int) time.Duration {
+	ttl := s.config.DefaultTTL
+	if hours > 0 {
+		ttl = time.Duration(hours) * time.Hour
+	}
+	if s.config.MaxTTL > 0 && ttl > s.config.MaxTTL {
+		ttl = s.config.MaxTTL
+	}
+	return ttl
+}
+
+func (s *shareLinkService) GetShareLinks(ctx context.Context, teacherID string, ownerID string, studentID string) ([]models.ShareLink, error) {
+
+	owner, err := cartOwner(ctx, s.members, teacherID, ownerID, studentID, models.CartPermissionView)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetShareLinks(ctx, owner, studentID)
+}
+
+// RevokeShareLink - Stops a link from working. The same access that allows sharing the cart allows revoking it.
+func (s *shareLinkService) RevokeShareLink(ctx context.Context, teacherID string, linkID string) error {
+
+	id, err := primitive.ObjectIDFromHex(linkID)
+	if err != nil {
+		return models.NewServiceError(http.StatusBadRequest, models.ErrInvalidRequest, "invalid share link ID", nil)
+	}
+
+	link, err := s.repo.GetShareLink(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get share link: %w", err)
+	}
+
+	if link == nil {
+		return models.NewServiceError(http.StatusNotFound, models.ErrShareLinkNotFound, "share link not found", nil)
+	}
+
+	if _, err := cartOwner(ctx, s.members, teacherID, link.TeacherID, link.StudentID, models.CartPermissionView); err != nil {
+		return err
+	}
+
+	if link.RevokedAt != nil {
+		return nil
+	}
+
+	return s.repo.RevokeShareLink(ctx, link)
+}
+
+// GetSharedCart - Serves the cart behind a share token. Tokens that are malformed, expired or revoked
+// all get the same answer.
+func (s *shareLinkService) GetSharedCart(ctx context.Context, token string) (*models.SharedCart, error) {
+
+	if err := s.ensureEnabled(); err != nil {
+		return nil, err
+	}
+
+	invalid := models.NewServiceError(http.StatusNotFound, models.ErrShareLinkInvalid, "share link is invalid or has expired", nil)
+
+	linkID, err := s.signer.Verify(token)
+	if err != nil {
+		return nil, invalid
+	}
+
+	id, err := primitive.ObjectIDFromHex(linkID)
+	if err != nil {
+		return nil, invalid
+	}
+
+	link, err := s.repo.GetShareLink(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share link: %w", err)
+	}
+
+	if link == nil || !link.Active(time.Now()) {
+		return nil, invalid
+	}
+
+	cart, err := s.sharedCart(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+
+	if cart == nil {
+		return &models.SharedCart{Items: []models.SharedCartItem{}, ExpiresAt: link.ExpiresAt}, nil
+	}
+
+	return models.NewSharedCart(cart, link), nil
+}
+
+// sharedCart - The cart a link points at, or nil when the student has no such cart any more.
+func (s *shareLinkService) sharedCart(ctx context.Context, link *models.ShareLink) (*models.Cart, error) {
+
+	if link.CartID != nil {
+		cart, err := s.repoCart.GetCartByID(ctx, link.TeacherID, *link.CartID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cart: %w", err)
+		}
+		return cart, nil
+	}
+
+	carts, err := s.repoCart.GetCartsByStudent(ctx, link.TeacherID, link.StudentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get carts: %w", err)
+	}
+
+	for i := range carts {
+		if !carts[i].Named {
+			return &carts[i], nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"store/internal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// stockFor - The stock that limits a line and the key under which lines sharing it are counted.
+// A variation with its own stock is counted separately; otherwise the product stock applies.
+// ok is false when neither is tracked.
+func (p *productSnapshot) stockFor(selection *models.VariationSelection) (key string, available int, ok bool) {
+	if selection != nil {
+		if variation, found := p.findVariation(selection); found && variation.Stock != nil {
+			return p.ID.Hex() + "|" + selection.VariationName + "|" + selection.Option, *variation.Stock, true
+		}
+	}
+	if p.Stock != nil {
+		return p.ID.Hex(), *p.Stock, true
+	}
+	return "", 0, false
+}
+
+// stockDemand - Units wanted per stock key, and units wanted at the promotional price per product,
+// summed over the carts that are ordered together.
+type stockDemand struct {
+	units       map[string]int
+	promotional map[primitive.ObjectID]int
+}
+
+func newStockDemand() *stockDemand {
+	return &stockDemand{
+		units:       map[string]int{},
+		promotional: map[primitive.ObjectID]int{},
+	}
+}
+
+func (d *stockDemand) add(product *productSnapshot, item models.CartItem, quantity int) {
+	if key, _, ok := product.stockFor(item.Variation); ok {
+		d.units[key] += quantity
+	}
+	if item.PromotionApplied {
+		d.promotional[product.ID] += quantity
+	}
+}
+
+// checkStock - Compares the demand on the line's stock with what is available and marks the line.
+// It returns the shortfall, or nil when the line can be fulfilled.
+func (p *productSnapshot) checkStock(item *models.CartItem, demand *stockDemand, checkedAt time.Time) *models.StockDetail {
+	key, available, tracked := p.stockFor(item.Variation)
+
+	var detail *models.StockDetail
+	switch {
+	case tracked && available <= 0:
+		detail = p.stockDetail(item, demand.units[key], available, "out_of_stock")
+	case tracked && demand.units[key] > available:
+		detail = p.stockDetail(item, demand.units[key], available, "insufficient_stock")
+	case item.PromotionApplied && p.PromotionStock != nil && demand.promotional[p.ID] > *p.PromotionStock:
+		detail = p.stockDetail(item, demand.promotional[p.ID], *p.PromotionStock, "insufficient_promotion_stock")
+	}
+
+	var remaining *int
+	if tracked {
+		remaining = &available
+	}
+	item.MarkAvailability(detail == nil, remaining, checkedAt)
+
+	return detail
+}
+
+func (p *productSnapshot) stockDetail(item *models.CartItem, requested int, available int, reason string) *models.StockDetail {
+	return &models.StockDetail{
+		ProductID:   p.ID.Hex(),
+		ProductName: p.Name,
+		Variation:   item.Variation,
+		Requested:   requested,
+		Available:   max(available, 0),
+		Reason:      reason,
+	}
+}
+
+// ensureInStock - Checks that requested more units of the line can be fulfilled on top of what the
+// carts ordered together with cart already hold, and records the outcome on the line.
+func (s *cartService) ensureInStock(ctx context.Context, cart *models.Cart, product *productSnapshot, item *models.CartItem, requested int) error {
+	carts, err := s.checkoutGroup(ctx, cart)
+	if err != nil {
+		return err
+	}
+
+	return checkLineStock(cart, carts, product, item, requested)
+}
+
+// checkLineStock - The check behind ensureInStock, against an already loaded checkout group.
+func checkLineStock(cart *models.Cart, carts []models.Cart, product *productSnapshot, item *models.CartItem, requested int) error {
+	demand := newStockDemand()
+	for _, grouped := range carts {
+		for _, line := range grouped.Items {
+			if line.ProductID == product.ID {
+				demand.add(product, line, line.Quantity)
+			}
+		}
+	}
+	demand.add(product, *item, requested)
+
+	detail := product.checkStock(item, demand, time.Now())
+	if detail == nil {
+		return nil
+	}
+
+	detail.StudentID = cart.StudentID
+	return models.NewServiceError(http.StatusConflict, models.ErrInsufficientStock, fmt.Sprintf("not enough stock for %s: %d requested, %d available", product.Name, detail.Requested, detail.Available), []models.StockDetail{*detail})
+}
+
+// ensureCartsInStock - Re-checks every line before checkout. When any line falls short, the
+// availability of all lines is saved so the carts show what needs attention.
+func (s *cartService) ensureCartsInStock(ctx context.Context, carts []models.Cart, products map[primitive.ObjectID]*productSnapshot) error {
+	demand := newStockDemand()
+	for _, cart := range carts {
+		for _, item := range cart.Items {
+			demand.add(products[item.ProductID], item, item.Quantity)
+		}
+	}
+
+	checkedAt := time.Now()
+	var shortfalls []models.StockDetail
+	for c := range carts {
+		for i := range carts[c].Items {
+			item := &carts[c].Items[i]
+			if detail := products[item.ProductID].checkStock(item, demand, checkedAt); detail != nil {
+				detail.StudentID = carts[c].StudentID
+				shortfalls = append(shortfalls, *detail)
+			}
+		}
+	}
+
+	if len(shortfalls) == 0 {
+		return nil
+	}
+
+	for c := range carts {
+		if len(carts[c].Items) == 0 {
+			continue
+		}
+		if err := s.repoCart.UpdateCart(ctx, &carts[c]); err != nil {
+			return fmt.Errorf("failed to save stock availability: %w", err)
+		}
+	}
+
+	return models.NewServiceError(http.StatusConflict, models.ErrInsufficientStock, "some cart items are out of stock", shortfalls)
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"store/internal/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCheckLineStock(t *testing.T) {
+	stock := func(n int) *int { return &n }
+	productID := primitive.NewObjectID()
+	medium := &models.VariationSelection{VariationName: "Size", Option: "M"}
+	large := &models.VariationSelection{VariationName: "Size", Option: "L"}
+
+	tests := []struct {
+		name       string
+		product    productSnapshot
+		held       []models.CartItem
+		item       models.CartItem
+		requested  int
+		wantReason string
+		wantLeft   *int
+	}{
+		{
+			name:      "untracked stock",
+			product:   productSnapshot{ID: productID},
+			held:      []models.CartItem{{ProductID: productID, Quantity: 100}},
+			item:      models.CartItem{ProductID: productID},
+			requested: 100,
+		},
+		{
+			name:      "enough product stock",
+			product:   productSnapshot{ID: productID, Stock: stock(5)},
+			held:      []models.CartItem{{ProductID: productID, Quantity: 2}},
+			item:      models.CartItem{ProductID: productID},
+			requested: 3,
+			wantLeft:  stock(5),
+		},
+		{
+			name:       "other carts of the group hold the stock",
+			product:    productSnapshot{ID: productID, Stock: stock(5)},
+			held:       []models.CartItem{{ProductID: productID, Quantity: 4}},
+			item:       models.CartItem{ProductID: productID},
+			requested:  2,
+			wantReason: "insufficient_stock",
+		},
+		{
+			name:       "out of stock",
+			product:    productSnapshot{ID: productID, Stock: stock(0)},
+			item:       models.CartItem{ProductID: productID},
+			requested:  1,
+			wantReason: "out_of_stock",
+		},
+		{
+			name: "variation counted on its own stock",
+			product: productSnapshot{ID: productID, Stock: stock(10), Variations: []productVariation{
+				{VariationName: "Size", Option: "M", Stock: stock(1)},
+				{VariationName: "Size", Option: "L", Stock: stock(5)},
+			}},
+			held:       []models.CartItem{{ProductID: productID, Variation: large, Quantity: 4}},
+			item:       models.CartItem{ProductID: productID, Variation: medium},
+			requested:  2,
+			wantReason: "insufficient_stock",
+		},
+		{
+			name: "variation without stock uses the product stock",
+			product: productSnapshot{ID: productID, Stock: stock(3), Variations: []productVariation{
+				{VariationName: "Size", Option: "M"},
+			}},
+			held:       []models.CartItem{{ProductID: productID, Quantity: 2}},
+			item:       models.CartItem{ProductID: productID, Variation: medium},
+			requested:  2,
+			wantReason: "insufficient_stock",
+		},
+		{
+			name:       "promotion stock",
+			product:    productSnapshot{ID: productID, Stock: stock(10), PromotionStock: stock(2)},
+			held:       []models.CartItem{{ProductID: productID, Quantity: 2, PromotionApplied: true}},
+			item:       models.CartItem{ProductID: productID, PromotionApplied: true},
+			requested:  1,
+			wantReason: "insufficient_promotion_stock",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := &models.Cart{StudentID: "s1"}
+			carts := []models.Cart{{StudentID: "s2", Items: tt.held}}
+			item := tt.item
+
+			err := checkLineStock(cart, carts, &tt.product, &item, tt.requested)
+
+			if item.Available == nil || *item.Available != (tt.wantReason == "") {
+				t.Errorf("line available = %v, want %v", item.Available, tt.wantReason == "")
+			}
+
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Fatalf("checkLineStock() error = %v", err)
+				}
+				if !reflect.DeepEqual(item.StockAvailable, tt.wantLeft) {
+					t.Errorf("stock available = %v, want %v", item.StockAvailable, tt.wantLeft)
+				}
+				return
+			}
+
+			var serviceErr *models.ServiceError
+			if !errors.As(err, &serviceErr) || serviceErr.StatusCode != http.StatusConflict || serviceErr.ErrorCode != models.ErrInsufficientStock {
+				t.Fatalf("checkLineStock() error = %v, want a 409 %s", err, models.ErrInsufficientStock)
+			}
+			details := serviceErr.Details.([]models.StockDetail)
+			if len(details) != 1 || details[0].Reason != tt.wantReason || details[0].StudentID != "s1" {
+				t.Errorf("details = %+v, want one %q line for s1", details, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestEnsureCartsInStock(t *testing.T) {
+	stock := 3
+	pen := &productSnapshot{ID: primitive.NewObjectID(), Name: "Pen", Stock: &stock}
+	book := &productSnapshot{ID: primitive.NewObjectID(), Name: "Book"}
+	products := map[primitive.ObjectID]*productSnapshot{pen.ID: pen, book.ID: book}
+
+	tests := []struct {
+		name         string
+		quantities   [2]int
+		wantStudents []string
+	}{
+		{name: "every line can be fulfilled", quantities: [2]int{1, 2}},
+		{name: "lines sharing the stock fall short together", quantities: [2]int{2, 2}, wantStudents: []string{"s1", "s2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeCartRepository()
+			carts := []models.Cart{
+				repo.put(models.Cart{TeacherID: "t1", StudentID: "s1", Items: []models.CartItem{{ProductID: pen.ID, Quantity: tt.quantities[0]}, {ProductID: book.ID, Quantity: 1}}}),
+				repo.put(models.Cart{TeacherID: "t1", StudentID: "s2", Items: []models.CartItem{{ProductID: pen.ID, Quantity: tt.quantities[1]}}}),
+			}
+			s := &cartService{repoCart: repo}
+
+			err := s.ensureCartsInStock(context.Background(), carts, products)
+
+			if tt.wantStudents == nil {
+				if err != nil {
+					t.Fatalf("ensureCartsInStock() error = %v", err)
+				}
+				if stored := repo.get(carts[0].ID); stored.Items[0].Available != nil {
+					t.Errorf("carts were saved although every line can be fulfilled")
+				}
+				return
+			}
+
+			var serviceErr *models.ServiceError
+			if !errors.As(err, &serviceErr) || serviceErr.ErrorCode != models.ErrInsufficientStock {
+				t.Fatalf("ensureCartsInStock() error = %v, want %s", err, models.ErrInsufficientStock)
+			}
+
+			var students []string
+			for _, detail := range serviceErr.Details.([]models.StockDetail) {
+				students = append(students, detail.StudentID)
+			}
+			if !reflect.DeepEqual(students, tt.wantStudents) {
+				t.Errorf("short lines for %v, want %v", students, tt.wantStudents)
+			}
+
+			stored := repo.get(carts[0].ID)
+			if pen, book := stored.Items[0].Available, stored.Items[1].Available; pen == nil || *pen || book == nil || !*book {
+				t.Errorf("saved availability = %v, %v, want the pen short and the book available", pen, book)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"store/config"
+	"store/pkg/auth"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// StudentDirectory - Answers whether a student is taught by a teacher.
+type StudentDirectory interface {
+	IsTeacherOfStudent(ctx context.Context, teacherID string, studentID string) (bool, error)
+}
+
+var userService = "user-service"
+
+// NewStudentDirectory - Builds the directory selected in the configuration, wrapped in a lookup cache.
+func NewStudentDirectory(cfg config.StudentDirectoryConfig, client *api.Client, signer *auth.ServiceTokenSigner) (StudentDirectory, error) {
+	var directory StudentDirectory
+
+	switch cfg.Source {
+	case "file":
+		fileDirectory, err := NewFileStudentDirectory(cfg.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		directory = fileDirectory
+	case "", userService:
+		directory = NewUserServiceStudentDirectory(NewServiceAPI(client, userService, signer))
+	default:
+		return nil, fmt.Errorf("unsupported student directory source: %s", cfg.Source)
+	}
+
+	if cfg.CacheTTL <= 0 {
+		return directory, nil
+	}
+
+	return NewCachedStudentDirectory(directory, cfg.CacheTTL), nil
+}
+
+// userServiceStudentDirectory - Reads teacher–student links from the Consul-discovered user-service.
+type userServiceStudentDirectory struct {
+	userAPI *callAPI
+}
+
+func NewUserServiceStudentDirectory(userAPI *callAPI) StudentDirectory {
+	return &userServiceStudentDirectory{userAPI: userAPI}
+}
+
+func (d *userServiceStudentDirectory) IsTeacherOfStudent(ctx context.Context, teacherID string, studentID string) (bool, error) {
+	if d.userAPI == nil {
+		return false, fmt.Errorf("%s is not available", userService)
+	}
+
+	studentIDs, err := d.userAPI.GetStudentIDsByTeacher(ctx, teacherID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, id := range studentIDs {
+		if id == studentID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (c *callAPI) GetStudentIDsByTeacher(ctx context.Context, teacherID string) ([]string, error) {
+	headers, err := c.authHeaders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("/api/v1/teachers/%s/students", teacherID)
+	res, err := c.client.CallAPI(c.clientServer, endpoint, http.MethodGet, nil, headers)
+	if err != nil {
+		return nil, fmt.Errorf("error calling API: %v", err)
+	}
+
+	var response struct {
+		StatusCode int               `json:"status_code"`
+		Error      string            `json:"error"`
+		Data       []json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(res), &response); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %v", err)
+	}
+
+	if response.StatusCode >= 400 {
+		return nil, fmt.Errorf("API Error: %v, Status: %v", response.Error, response.StatusCode)
+	}
+
+	// Students may be returned either as plain IDs or as objects carrying their ID.
+	studentIDs := make([]string, 0, len(response.Data))
+	for _, raw := range response.Data {
+		var id string
+		if err := json.Unmarshal(raw, &id); err == nil {
+			studentIDs = append(studentIDs, id)
+			continue
+		}
+
+		var student struct {
+			ID        string `json:"id"`
+			ObjectID  string `json:"_id"`
+			StudentID string `json:"student_id"`
+		}
+		if err := json.Unmarshal(raw, &student); err != nil {
+			continue
+		}
+		switch {
+		case student.StudentID != "":
+			studentIDs = append(studentIDs, student.StudentID)
+		case student.ID != "":
+			studentIDs = append(studentIDs, student.ID)
+		case student.ObjectID != "":
+			studentIDs = append(studentIDs, student.ObjectID)
+		}
+	}
+
+	return studentIDs, nil
+}
+
+// fileStudentDirectory - Stand-in for local runs, backed by a JSON file mapping teacher IDs to student IDs.
+type fileStudentDirectory struct {
+	students map[string]map[string]bool
+}
+
+func NewFileStudentDirectory(path string) (StudentDirectory, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read student directory file: %w", err)
+	}
+
+	var links map[string][]string
+	if err := json.Unmarshal(raw, &links); err != nil {
+		return nil, fmt.Errorf("failed to decode student directory file: %w", err)
+	}
+
+	students := make(map[string]map[string]bool, len(links))
+	for teacherID, studentIDs := range links {
+		students[teacherID] = make(map[string]bool, len(studentIDs))
+		for _, studentID := range studentIDs {
+			students[teacherID][studentID] = true
+		}
+	}
+
+	return &fileStudentDirectory{students: students}, nil
+}
+
+func (d *fileStudentDirectory) IsTeacherOfStudent(ctx context.Context, teacherID string, studentID string) (bool, error) {
+	return d.students[teacherID][studentID], nil
+}
+
+// cachedStudentDirectory - Remembers lookup results for a while. Failed lookups are not cached.
+// Stale entries are dropped when they are looked up, and all expired entries are swept at most
+// once per TTL, so the cache only holds pairs looked up recently.
+type cachedStudentDirectory struct {
+	next      StudentDirectory
+	ttl       time.Duration
+	mu        sync.Mutex
+	entries   map[string]cachedLink
+	lastSweep time.Time
+}
+
+type cachedLink struct {
+	linked    bool
+	expiresAt time.Time
+}
+
+func NewCachedStudentDirectory(next StudentDirectory, ttl time.Duration) StudentDirectory {
+	return &cachedStudentDirectory{
+		next:      next,
+		ttl:       ttl,
+		entries:   make(map[string]cachedLink),
+		lastSweep: time.Now(),
+	}
+}
+
+func (d *cachedStudentDirectory) IsTeacherOfStudent(ctx context.Context, teacherID string, studentID string) (bool, error) {
+	key := teacherID + "|" + studentID
+
+	d.mu.Lock()
+	entry, ok := d.entries[key]
+	if ok && !time.Now().Before(entry.expiresAt) {
+		delete(d.entries, key)
+		ok = false
+	}
+	d.mu.Unlock()
+
+	if ok {
+		return entry.linked, nil
+	}
+
+	linked, err := d.next.IsTeacherOfStudent(ctx, teacherID, studentID)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+
+	d.mu.Lock()
+	if now.Sub(d.lastSweep) >= d.ttl {
+		d.sweep(now)
+	}
+	d.entries[key] = cachedLink{linked: linked, expiresAt: now.Add(d.ttl)}
+	d.mu.Unlock()
+
+	return linked, nil
+}
+
+// sweep - Drops every expired entry. The caller holds d.mu.
+func (d *cachedStudentDirectory) sweep(now time.Time) {
+	for key, entry := range d.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(d.entries, key)
+		}
+	}
+	d.lastSweep = now
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingDirectory - A StudentDirectory that counts lookups and fails while err is set.
+type countingDirectory struct {
+	links   map[string]bool
+	err     error
+	lookups int
+}
+
+func (d *countingDirectory) IsTeacherOfStudent(ctx context.Context, teacherID string, studentID string) (bool, error) {
+	d.lookups++
+	if d.err != nil {
+		return false, d.err
+	}
+	return d.links[teacherID+"|"+studentID], nil
+}
+
+func TestCachedStudentDirectory(t *testing.T) {
+	ctx := context.Background()
+	next := &countingDirectory{links: map[string]bool{"t1|s1": true}}
+	cache := NewCachedStudentDirectory(next, time.Hour).(*cachedStudentDirectory)
+
+	for i := 0; i < 3; i++ {
+		linked, err := cache.IsTeacherOfStudent(ctx, "t1", "s1")
+		if err != nil || !linked {
+			t.Fatalf("IsTeacherOfStudent() = %v, %v, want true, nil", linked, err)
+		}
+	}
+	if next.lookups != 1 {
+		t.Errorf("directory looked up %d times, want 1", next.lookups)
+	}
+
+	// Failed lookups are not cached.
+	next.err = errors.New("user-service unavailable")
+	if _, err := cache.IsTeacherOfStudent(ctx, "t1", "s2"); err == nil {
+		t.Fatal("IsTeacherOfStudent() error = nil, want an error")
+	}
+	if _, ok := cache.entries["t1|s2"]; ok {
+		t.Error("failed lookup was cached")
+	}
+}
+
+func TestCachedStudentDirectoryEviction(t *testing.T) {
+	ctx := context.Background()
+	next := &countingDirectory{links: map[string]bool{"t1|s1": true}}
+	cache := NewCachedStudentDirectory(next, time.Hour).(*cachedStudentDirectory)
+
+	for _, studentID := range []string{"s1", "s2", "s3"} {
+		if _, err := cache.IsTeacherOfStudent(ctx, "t1", studentID); err != nil {
+			t.Fatalf("IsTeacherOfStudent: %v", err)
+		}
+	}
+
+	expired := time.Now().Add(-time.Minute)
+	for key, entry := range cache.entries {
+		entry.expiresAt = expired
+		cache.entries[key] = entry
+	}
+
+	// A stale entry is dropped and looked up again.
+	if _, err := cache.IsTeacherOfStudent(ctx, "t1", "s1"); err != nil {
+		t.Fatalf("IsTeacherOfStudent: %v", err)
+	}
+	if next.lookups != 4 {
+		t.Errorf("directory looked up %d times, want 4", next.lookups)
+	}
+
+	// Once a TTL has passed since the last sweep, the other expired entries go too.
+	cache.lastSweep = time.Now().Add(-2 * time.Hour)
+	if _, err := cache.IsTeacherOfStudent(ctx, "t2", "s1"); err != nil {
+		t.Fatalf("IsTeacherOfStudent: %v", err)
+	}
+	if len(cache.entries) != 2 {
+		t.Errorf("cache holds %d entries, want 2: %v", len(cache.entries), cache.entries)
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"store/internal/models"
+)
+
+const (
+	usageBudgetWarn   = "warn"
+	usageBudgetReject = "reject"
+)
+
+// plannedMinimumUsageTime - The minimum usage time, in minutes, that quantity more units of the line add.
+func plannedMinimumUsageTime(item *models.CartItem, quantity int) int {
+	if item.UsageConfig == nil {
+		return 0
+	}
+	return item.UsageConfig.MinimumUsageTime * item.UsageConfig.NumberOfUses * quantity
+}
+
+// planningWeeks - The number of weeks a cart's planned uses are spread over, at least one.
+func (s *cartService) planningWeeks() int {
+	if s.cartConfig.UsagePlanningWeeks < 1 {
+		return 1
+	}
+	return s.cartConfig.UsagePlanningWeeks
+}
+
+// weeklyUsageTime - The minutes per week that total minutes of planned usage come to when spread
+// evenly over weeks, rounded up.
+func weeklyUsageTime(total int, weeks int) int {
+	return (total + weeks - 1) / weeks
+}
+
+// usageBudgetMessage - Describes planned weekly usage that goes past the budget.
+func usageBudgetMessage(weekly int, budget int, weeks int) string {
+	if weeks == 1 {
+		return fmt.Sprintf("planned usage of %d minutes a week exceeds the weekly budget of %d minutes", weekly, budget)
+	}
+	return fmt.Sprintf("planned usage of %d minutes a week over %d weeks exceeds the weekly budget of %d minutes", weekly, weeks, budget)
+}
+
+// ensureWithinUsageBudget - In reject mode, refuses changes that take the planned minimum usage
+// time per week of a student's cart past the weekly usage budget.
+func (s *cartService) ensureWithinUsageBudget(cart *models.Cart, item *models.CartItem, requested int) error {
+	budget := s.cartConfig.WeeklyUsageBudget
+	if budget <= 0 || s.cartConfig.UsageBudgetMode != usageBudgetReject {
+		return nil
+	}
+
+	added := plannedMinimumUsageTime(item, requested)
+	if added == 0 {
+		return nil
+	}
+
+	cart.CalculateTotals()
+
+	weeks := s.planningWeeks()
+	planned := weeklyUsageTime(cart.TotalMinimumUsageTime, weeks)
+	projected := weeklyUsageTime(cart.TotalMinimumUsageTime+added, weeks)
+	if projected <= budget {
+		return nil
+	}
+
+	return models.NewServiceError(http.StatusConflict, models.ErrUsageBudget, usageBudgetMessage(projected, budget, weeks), models.UsageBudgetDetail{
+		StudentID:     cart.StudentID,
+		WeeklyBudget:  budget,
+		PlanningWeeks: weeks,
+		Planned:       planned,
+		Projected:     projected,
+	})
+}
+
+// refreshUsageWarning - In warn mode, flags a cart whose planned minimum usage time per week goes past
+// the weekly usage budget after a change, and clears the flag once it is back within it.
+func (s *cartService) refreshUsageWarning(ctx context.Context, cart *models.Cart) error {
+	budget := s.cartConfig.WeeklyUsageBudget
+	if budget <= 0 || s.cartConfig.UsageBudgetMode != usageBudgetWarn {
+		return nil
+	}
+
+	cart.CalculateTotals()
+
+	weeks := s.planningWeeks()
+	if weekly := weeklyUsageTime(cart.TotalMinimumUsageTime, weeks); weekly > budget {
+		cart.SetWarning(models.WarningUsageBudget, usageBudgetMessage(weekly, budget, weeks))
+	} else {
+		cart.ClearWarning(models.WarningUsageBudget)
+	}
+
+	return s.repoCart.UpdateCart(ctx, cart)
+}
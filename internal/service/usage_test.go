@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"store/config"
+	"store/internal/models"
+	"testing"
+)
+
+func TestEnsureWithinUsageBudget(t *testing.T) {
+	// Each unit plans 4 uses of at least 30 minutes: 120 minutes.
+	line := models.CartItem{Quantity: 1, UsageConfig: &models.UsageConfig{NumberOfUses: 4, MinimumUsageTime: 30, MaximumUsageTime: 45}}
+
+	tests := []struct {
+		name          string
+		budget        int
+		weeks         int
+		mode          string
+		inCart        int
+		requested     int
+		wantProjected int
+	}{
+		{name: "disabled", budget: 0, weeks: 1, mode: usageBudgetReject, inCart: 10, requested: 1},
+		{name: "warn mode never rejects", budget: 60, weeks: 1, mode: usageBudgetWarn, inCart: 1, requested: 1},
+		{name: "within one week", budget: 240, weeks: 1, mode: usageBudgetReject, inCart: 1, requested: 1},
+		{name: "past one week", budget: 240, weeks: 1, mode: usageBudgetReject, inCart: 2, requested: 1, wantProjected: 360},
+		{name: "spread over a term", budget: 120, weeks: 10, mode: usageBudgetReject, inCart: 5, requested: 5},
+		{name: "past the budget over a term", budget: 100, weeks: 10, mode: usageBudgetReject, inCart: 5, requested: 4, wantProjected: 108},
+		{name: "weeks below one count as one", budget: 240, weeks: 0, mode: usageBudgetReject, inCart: 2, requested: 1, wantProjected: 360},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &cartService{cartConfig: config.CartConfig{WeeklyUsageBudget: tt.budget, UsagePlanningWeeks: tt.weeks, UsageBudgetMode: tt.mode}}
+
+			item := line
+			cart := &models.Cart{StudentID: "student-1", Items: []models.CartItem{item}}
+			cart.Items[0].Quantity = tt.inCart
+
+			err := s.ensureWithinUsageBudget(cart, &item, tt.requested)
+			if tt.wantProjected == 0 {
+				if err != nil {
+					t.Fatalf("ensureWithinUsageBudget: %v", err)
+				}
+				return
+			}
+
+			var serviceErr *models.ServiceError
+			if !errors.As(err, &serviceErr) || serviceErr.ErrorCode != models.ErrUsageBudget {
+				t.Fatalf("ensureWithinUsageBudget() error = %v, want %s", err, models.ErrUsageBudget)
+			}
+			detail, _ := serviceErr.Details.(models.UsageBudgetDetail)
+			if detail.Projected != tt.wantProjected {
+				t.Errorf("projected = %d minutes a week, want %d", detail.Projected, tt.wantProjected)
+			}
+		})
+	}
+}
+
+func TestWeeklyUsageTime(t *testing.T) {
+	tests := []struct {
+		total, weeks, want int
+	}{
+		{total: 0, weeks: 1, want: 0},
+		{total: 120, weeks: 1, want: 120},
+		{total: 120, weeks: 12, want: 10},
+		{total: 121, weeks: 12, want: 11},
+	}
+
+	for _, tt := range tests {
+		if got := weeklyUsageTime(tt.total, tt.weeks); got != tt.want {
+			t.Errorf("weeklyUsageTime(%d, %d) = %d, want %d", tt.total, tt.weeks, got, tt.want)
+		}
+	}
+}
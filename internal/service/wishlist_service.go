@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"store/internal/models"
+	"store/internal/repository"
+	"store/pkg/auth"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type WishlistService interface {
+	CreateWishlist(ctx context.Context, req *models.CreateWishlistRequest) (*models.Wishlist, error)
+	GetWishlists(ctx context.Context, teacherID string) ([]models.Wishlist, error)
+	GetWishlist(ctx context.Context, teacherID string, wishlistID string) (*models.WishlistReport, error)
+	DeleteWishlist(ctx context.Context, teacherID string, wishlistID string) error
+	AddItem(ctx context.Context, wishlistID string, req *models.AddWishlistItemRequest) (*models.WishlistEntry, error)
+	RemoveItem(ctx context.Context, wishlistID string, productID string, req *models.WishlistItemRequest) error
+	MoveToCart(ctx context.Context, wishlistID string, productID string, req *models.MoveWishlistItemRequest) (*models.CartItem, error)
+}
+
+type wishlistService struct {
+	repo        repository.WishlistRepository
+	cartService CartService
+	productAPI  *callAPI
+	students    StudentDirectory
+}
+
+func NewWishlistService(repo repository.WishlistRepository, cartService CartService, client *api.Client, students StudentDirectory, signer *auth.ServiceTokenSigner) WishlistService {
+	return &wishlistService{
+		repo:        repo,
+		cartService: cartService,
+		productAPI:  NewServiceAPI(client, productService, signer),
+		students:    students,
+	}
+}
+
+func (s *wishlistService) CreateWishlist(ctx context.Context, req *models.CreateWishlistRequest) (*models.Wishlist, error) {
+
+	if req.StudentID != "" {
+		if err := ensureTeacherOfStudent(ctx, s.students, req.TeacherID, req.StudentID); err != nil {
+			return nil, err
+		}
+	}
+
+	wishlist := &models.Wishlist{
+		TeacherID: req.TeacherID,
+		StudentID: req.StudentID,
+		Name:      req.Name,
+	}
+
+	if err := s.repo.CreateWishlist(ctx, wishlist); err != nil {
+		return nil, fmt.Errorf("failed to create wishlist: %w", err)
+	}
+
+	return wishlist, nil
+}
+
+func (s *wishlistService) GetWishlists(ctx context.Context, teacherID string) ([]models.Wishlist, error) {
+	return s.repo.GetWishlistsByTeacher(ctx, teacherID)
+}
+
+// GetWishlist - Returns the wishlist with each entry checked against the current product.
+func (s *wishlistService) GetWishlist(ctx context.Context, teacherID string, wishlistID string) (*models.WishlistReport, error) {
+
+	wishlist, err := s.getWishlist(ctx, teacherID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.WishlistReport{
+		Wishlist: *wishlist,
+		Items:    make([]models.WishlistEntryStatus, 0, len(wishlist.Items)),
+	}
+
+	for _, entry := range wishlist.Items {
+		status, err := s.entryStatus(ctx, entry)
+		if err != nil {
+			return nil, err
+		}
+		report.Items = append(report.Items, *status)
+	}
+
+	return report, nil
+}
+
+func (s *wishlistService) DeleteWishlist(ctx context.Context, teacherID string, wishlistID string) error {
+
+	id, err := primitive.ObjectIDFromHex(wishlistID)
+	if err != nil {
+		return fmt.Errorf("invalid wishlist ID")
+	}
+
+	deleted, err := s.repo.DeleteWishlist(ctx, teacherID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete wishlist: %w", err)
+	}
+
+	if !deleted {
+		return models.NewServiceError(http.StatusNotFound, models.ErrWishlistNotFound, "wishlist not found", nil)
+	}
+
+	return nil
+}
+
+func (s *wishlistService) AddItem(ctx context.Context, wishlistID string, req *models.AddWishlistItemRequest) (*models.WishlistEntry, error) {
+
+	wishlist, err := s.getWishlist(ctx, req.TeacherID, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	productID, err := primitive.ObjectIDFromHex(req.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product ID format: %v", err)
+	}
+
+	product, err := s.productAPI.GetProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	cartItem, err := product.cartItem(1, req.Variation)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := models.WishlistEntry{
+		ProductID:    cartItem.ProductID,
+		Variation:    cartItem.Variation,
+		ProductName:  cartItem.ProductName,
+		TopicName:    cartItem.TopicName,
+		CategoryName: cartItem.CategoryName,
+		PriceStore:   cartItem.PriceStore,
+		PriceService: cartItem.PriceService,
+		ImageURL:     cartItem.ImageURL,
+		AddedAt:      time.Now(),
+	}
+
+	if err := s.repo.AddWishlistItem(ctx, req.TeacherID, wishlist.ID, entry); err != nil {
+		return nil, fmt.Errorf("failed to add wishlist item: %w", err)
+	}
+
+	return &entry, nil
+}
+
+func (s *wishlistService) RemoveItem(ctx context.Context, wishlistID string, productID string, req *models.WishlistItemRequest) error {
+
+	wishlist, entry, err := s.getEntry(ctx, req.TeacherID, wishlistID, productID, req.Variation)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.RemoveWishlistItem(ctx, req.TeacherID, wishlist.ID, entry.ProductID, entry.Variation)
+}
+
+// MoveToCart - Adds the entry to a student's cart through the normal add-to-cart path, so it is
+// priced and checked like any other item, then takes it off the wishlist.
+func (s *wishlistService) MoveToCart(ctx context.Context, wishlistID string, productID string, req *models.MoveWishlistItemRequest) (*models.CartItem, error) {
+
+	wishlist, entry, err := s.getEntry(ctx, req.TeacherID, wishlistID, productID, req.Variation)
+	if err != nil {
+		return nil, err
+	}
+
+	studentID := req.StudentID
+	if studentID == "" {
+		studentID = wishlist.StudentID
+	}
+	if studentID == "" {
+		return nil, models.NewServiceError(http.StatusBadRequest, models.ErrInvalidRequest, "student ID is required for a wishlist that is not kept for a student", nil)
+	}
+
+	cartItem, err := s.cartService.AddToCart(ctx, &models.AddToCartRequest{
+		ProductID: entry.ProductID.Hex(),
+		TeacherID: req.TeacherID,
+		StudentID: studentID,
+		Quantity:  req.Quantity,
+		Variation: entry.Variation,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RemoveWishlistItem(ctx, req.TeacherID, wishlist.ID, entry.ProductID, entry.Variation); err != nil {
+		return nil, fmt.Errorf("failed to remove wishlist item: %w", err)
+	}
+
+	return cartItem, nil
+}
+
+func (s *wishlistService) getWishlist(ctx context.Context, teacherID string, wishlistID string) (*models.Wishlist, error) {
+
+	id, err := primitive.ObjectIDFromHex(wishlistID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wishlist ID")
+	}
+
+	wishlist, err := s.repo.GetWishlist(ctx, teacherID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wishlist: %w", err)
+	}
+
+	if wishlist == nil {
+		return nil, models.NewServiceError(http.StatusNotFound, models.ErrWishlistNotFound, "wishlist not found", nil)
+	}
+
+	return wishlist, nil
+}
+
+func (s *wishlistService) getEntry(ctx context.Context, teacherID string, wishlistID string, productID string, variation *models.VariationSelection) (*models.Wishlist, *models.WishlistEntry, error) {
+
+	wishlist, err := s.getWishlist(ctx, teacherID, wishlistID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid product ID")
+	}
+
+	for i, entry := range wishlist.Items {
+		if entry.SameLine(id, variation) {
+			return wishlist, &wishlist.Items[i], nil
+		}
+	}
+
+	return nil, nil, models.NewServiceError(http.StatusNotFound, models.ErrCartItemNotFound, "product not found in wishlist", nil)
+}
+
+// entryStatus - Compares an entry with the current product. Products that are gone, variations that
+// were dropped and stock that ran out make the entry unavailable; other errors are returned.
+func (s *wishlistService) entryStatus(ctx context.Context, entry models.WishlistEntry) (*models.WishlistEntryStatus, error) {
+
+	product, err := s.productAPI.GetProduct(ctx, entry.ProductID)
+	if err != nil {
+		var serviceErr *models.ServiceError
+		if errors.As(err, &serviceErr) && serviceErr.ErrorCode == models.ErrProductNotFound {
+			return &models.WishlistEntryStatus{WishlistEntry: entry, Reason: "product_not_found"}, nil
+		}
+		return nil, err
+	}
+
+	return wishlistEntryStatus(entry, product), nil
+}
+
+// wishlistEntryStatus - The part of entryStatus that compares the entry with the fetched product.
+func wishlistEntryStatus(entry models.WishlistEntry, product *productSnapshot) *models.WishlistEntryStatus {
+
+	status := &models.WishlistEntryStatus{WishlistEntry: entry}
+
+	current, err := product.cartItem(1, entry.Variation)
+	if err != nil {
+		status.Reason = "invalid_variation"
+		return status
+	}
+
+	status.CurrentPriceStore = current.PriceStore
+	status.CurrentPriceService = current.PriceService
+	status.PriceChanged = current.PriceStore != entry.PriceStore || current.PriceService != entry.PriceService
+
+	if _, available, tracked := product.stockFor(entry.Variation); tracked && available <= 0 {
+		status.Reason = "out_of_stock"
+		return status
+	}
+
+	status.Available = true
+	return status
+}
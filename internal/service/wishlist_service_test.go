@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"store/internal/models"
+	"store/internal/repository"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWishlistEntryStatus(t *testing.T) {
+	stock := func(n int) *int { return &n }
+	productID := primitive.NewObjectID()
+	medium := &models.VariationSelection{VariationName: "Size", Option: "M"}
+
+	tests := []struct {
+		name          string
+		entry         models.WishlistEntry
+		product       productSnapshot
+		wantReason    string
+		wantChanged   bool
+		wantCurrent   float64
+		wantAvailable bool
+	}{
+		{
+			name:          "unchanged",
+			entry:         models.WishlistEntry{ProductID: productID, PriceStore: 10},
+			product:       productSnapshot{ID: productID, PriceStore: 10},
+			wantCurrent:   10,
+			wantAvailable: true,
+		},
+		{
+			name:          "price changed",
+			entry:         models.WishlistEntry{ProductID: productID, PriceStore: 10},
+			product:       productSnapshot{ID: productID, PriceStore: 10, Promotion: &productPromotion{PercentOff: 20}},
+			wantChanged:   true,
+			wantCurrent:   8,
+			wantAvailable: true,
+		},
+		{
+			name:       "variation dropped",
+			entry:      models.WishlistEntry{ProductID: productID, Variation: medium, PriceStore: 10},
+			product:    productSnapshot{ID: productID, PriceStore: 10, Variations: []productVariation{{VariationName: "Size", Option: "L"}}},
+			wantReason: "invalid_variation",
+		},
+		{
+			name:        "variation out of stock",
+			entry:       models.WishlistEntry{ProductID: productID, Variation: medium, PriceStore: 10},
+			product:     productSnapshot{ID: productID, PriceStore: 10, Stock: stock(4), Variations: []productVariation{{VariationName: "Size", Option: "M", Stock: stock(0)}}},
+			wantReason:  "out_of_stock",
+			wantCurrent: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := wishlistEntryStatus(tt.entry, &tt.product)
+
+			if status.Available != tt.wantAvailable || status.Reason != tt.wantReason {
+				t.Errorf("available, reason = %v, %q, want %v, %q", status.Available, status.Reason, tt.wantAvailable, tt.wantReason)
+			}
+			if status.PriceChanged != tt.wantChanged || status.CurrentPriceStore != tt.wantCurrent {
+				t.Errorf("price changed, current = %v, %v, want %v, %v", status.PriceChanged, status.CurrentPriceStore, tt.wantChanged, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+// fakeWishlistRepository - Serves one wishlist and records the entries removed from it.
+type fakeWishlistRepository struct {
+	repository.WishlistRepository
+
+	wishlist models.Wishlist
+	removed  []primitive.ObjectID
+}
+
+func (r *fakeWishlistRepository) GetWishlist(ctx context.Context, teacherID string, wishlistID primitive.ObjectID) (*models.Wishlist, error) {
+	if r.wishlist.TeacherID != teacherID || r.wishlist.ID != wishlistID {
+		return nil, nil
+	}
+	wishlist := r.wishlist
+	return &wishlist, nil
+}
+
+func (r *fakeWishlistRepository) RemoveWishlistItem(ctx context.Context, teacherID string, wishlistID primitive.ObjectID, productID primitive.ObjectID, variation *models.VariationSelection) error {
+	r.removed = append(r.removed, productID)
+	return nil
+}
+
+// fakeAddToCart - A CartService whose AddToCart records the request and returns err.
+type fakeAddToCart struct {
+	CartService
+
+	req *models.AddToCartRequest
+	err error
+}
+
+func (s *fakeAddToCart) AddToCart(ctx context.Context, req *models.AddToCartRequest) (*models.CartItem, error) {
+	s.req = req
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.CartItem{Quantity: req.Quantity}, nil
+}
+
+func TestWishlistMoveToCart(t *testing.T) {
+	productID := primitive.NewObjectID()
+	medium := &models.VariationSelection{VariationName: "Size", Option: "M"}
+	outOfStock := models.NewServiceError(http.StatusConflict, models.ErrInsufficientStock, "not enough stock", nil)
+
+	tests := []struct {
+		name          string
+		listStudentID string
+		studentID     string
+		addErr        error
+		wantStudentID string
+		wantErrCode   string
+		wantRemoved   bool
+	}{
+		{name: "student of the wishlist", listStudentID: "s1", wantStudentID: "s1", wantRemoved: true},
+		{name: "student in the request", listStudentID: "s1", studentID: "s2", wantStudentID: "s2", wantRemoved: true},
+		{name: "no student", wantErrCode: models.ErrInvalidRequest},
+		{name: "refused by the cart", studentID: "s2", addErr: outOfStock, wantStudentID: "s2", wantErrCode: models.ErrInsufficientStock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeWishlistRepository{wishlist: models.Wishlist{
+				ID:        primitive.NewObjectID(),
+				TeacherID: "t1",
+				StudentID: tt.listStudentID,
+				Items:     []models.WishlistEntry{{ProductID: productID, Variation: medium}},
+			}}
+			carts := &fakeAddToCart{err: tt.addErr}
+			s := &wishlistService{repo: repo, cartService: carts}
+
+			req := &models.MoveWishlistItemRequest{TeacherID: "t1", StudentID: tt.studentID, Variation: medium, Quantity: 2}
+			item, err := s.MoveToCart(context.Background(), repo.wishlist.ID.Hex(), productID.Hex(), req)
+
+			if tt.wantErrCode != "" {
+				var serviceErr *models.ServiceError
+				if !errors.As(err, &serviceErr) || serviceErr.ErrorCode != tt.wantErrCode {
+					t.Errorf("MoveToCart() error = %v, want %s", err, tt.wantErrCode)
+				}
+			} else if err != nil || item == nil || item.Quantity != 2 {
+				t.Errorf("MoveToCart() = %+v, %v, want the added line", item, err)
+			}
+
+			if tt.wantStudentID != "" {
+				if carts.req == nil || carts.req.StudentID != tt.wantStudentID || carts.req.ProductID != productID.Hex() || !carts.req.Variation.Equal(medium) {
+					t.Errorf("add to cart request = %+v, want the entry for %s", carts.req, tt.wantStudentID)
+				}
+			} else if carts.req != nil {
+				t.Errorf("added to a cart without a student: %+v", carts.req)
+			}
+
+			if removed := len(repo.removed) > 0; removed != tt.wantRemoved {
+				t.Errorf("entry removed = %v, want %v", removed, tt.wantRemoved)
+			}
+		})
+	}
+}
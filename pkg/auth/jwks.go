@@ -0,0 +1,263 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrKeyNotFound = errors.New("signing key not found in key set")
+
+// minRefreshGap - Lower bound between two on-demand reloads triggered by an unknown kid.
+const minRefreshGap = 30 * time.Second
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// KeySet - Caches the signing keys of a JWKS document loaded from a file or an HTTP(S) URL.
+// The set is refreshed in the background; when a refresh fails the last good set keeps being served.
+type KeySet struct {
+	source     string
+	interval   time.Duration
+	httpClient *http.Client
+
+	// refreshMu lets only one on-demand reload run at a time.
+	refreshMu sync.Mutex
+
+	mu          sync.RWMutex
+	keys        map[string]interface{}
+	lastRefresh time.Time
+	lastErr     error
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewKeySet - Loads the key set once and starts the background refresh loop.
+// A zero or negative interval disables periodic refresh.
+func NewKeySet(source string, interval time.Duration) (*KeySet, error) {
+	ks := &KeySet{
+		source:     source,
+		interval:   interval,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		keys:       map[string]interface{}{},
+		stop:       make(chan struct{}),
+	}
+
+	if err := ks.Refresh(); err != nil {
+		return nil, err
+	}
+
+	if interval > 0 {
+		go ks.refreshLoop()
+	}
+
+	return ks, nil
+}
+
+// Key - Returns the key registered under kid. An unknown kid triggers a reload so that
+// freshly rotated keys are picked up without waiting for the next scheduled refresh.
+func (ks *KeySet) Key(kid string) (interface{}, error) {
+	if key, ok := ks.lookup(kid); ok {
+		return key, nil
+	}
+
+	ks.refreshMu.Lock()
+	defer ks.refreshMu.Unlock()
+
+	// Another request may have reloaded the set while this one waited.
+	if key, ok := ks.lookup(kid); ok {
+		return key, nil
+	}
+
+	ks.mu.RLock()
+	recent := time.Since(ks.lastRefresh) < minRefreshGap
+	ks.mu.RUnlock()
+
+	if !recent {
+		_ = ks.Refresh()
+		if key, ok := ks.lookup(kid); ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: kid %q", ErrKeyNotFound, kid)
+}
+
+// Refresh - Reloads the key set. On failure the previously loaded keys are kept.
+func (ks *KeySet) Refresh() error {
+	keys, err := ks.load()
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.lastRefresh = time.Now()
+	ks.lastErr = err
+	if err != nil {
+		return err
+	}
+
+	ks.keys = keys
+	return nil
+}
+
+// LastError - Returns the error of the most recent refresh, or nil if it succeeded.
+func (ks *KeySet) LastError() error {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.lastErr
+}
+
+// Close - Stops the background refresh loop.
+func (ks *KeySet) Close() {
+	ks.stopOnce.Do(func() {
+		close(ks.stop)
+	})
+}
+
+func (ks *KeySet) lookup(kid string) (interface{}, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+func (ks *KeySet) refreshLoop() {
+	ticker := time.NewTicker(ks.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = ks.Refresh()
+		case <-ks.stop:
+			return
+		}
+	}
+}
+
+func (ks *KeySet) load() (map[string]interface{}, error) {
+	raw, err := ks.fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	var set jsonWebKeySet
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode key set: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// A key this service cannot use must not hold back the others, or keys added during
+		// a rotation would never be picked up.
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.Printf("Skipping key %q in key set %s: %v", jwk.Kid, ks.source, err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("key set contains no signing keys")
+	}
+
+	return keys, nil
+}
+
+func (ks *KeySet) fetch() ([]byte, error) {
+	if !strings.HasPrefix(ks.source, "http://") && !strings.HasPrefix(ks.source, "https://") {
+		return os.ReadFile(strings.TrimPrefix(ks.source, "file://"))
+	}
+
+	resp, err := ks.httpClient.Get(ks.source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch key set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch key set: unexpected status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
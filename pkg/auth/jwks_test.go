@@ -0,0 +1,288 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"store/config"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func rsaJWK(t *testing.T, kid string) (*rsa.PrivateKey, jsonWebKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	return key, jsonWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(t *testing.T, kid string) (*ecdsa.PrivateKey, jsonWebKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ECDSA key: %v", err)
+	}
+	return key, jsonWebKey{
+		Kty: "EC",
+		Kid: kid,
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+	}
+}
+
+func writeKeySet(t *testing.T, keys ...jsonWebKey) string {
+	t.Helper()
+	raw, err := json.Marshal(jsonWebKeySet{Keys: keys})
+	if err != nil {
+		t.Fatalf("failed to encode key set: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatalf("failed to write key set: %v", err)
+	}
+	return path
+}
+
+func TestKeySetLoad(t *testing.T) {
+	_, rsaKey := rsaJWK(t, "rsa-1")
+	_, ecKey := ecJWK(t, "ec-1")
+
+	tests := []struct {
+		name     string
+		keys     []jsonWebKey
+		wantKids []string
+		wantErr  bool
+	}{
+		{
+			name:     "RSA and EC keys",
+			keys:     []jsonWebKey{rsaKey, ecKey},
+			wantKids: []string{"rsa-1", "ec-1"},
+		},
+		{
+			name:     "unsupported key type is skipped",
+			keys:     []jsonWebKey{{Kty: "OKP", Kid: "ed-1", Crv: "Ed25519"}, rsaKey},
+			wantKids: []string{"rsa-1"},
+		},
+		{
+			name:     "unsupported curve is skipped",
+			keys:     []jsonWebKey{{Kty: "EC", Kid: "ec-k1", Crv: "secp256k1"}, ecKey},
+			wantKids: []string{"ec-1"},
+		},
+		{
+			name:     "malformed key is skipped",
+			keys:     []jsonWebKey{{Kty: "RSA", Kid: "broken", N: "!!", E: "AQAB"}, rsaKey},
+			wantKids: []string{"rsa-1"},
+		},
+		{
+			name:     "encryption keys are ignored",
+			keys:     []jsonWebKey{{Kty: "RSA", Kid: "enc-1", Use: "enc", N: rsaKey.N, E: rsaKey.E}, ecKey},
+			wantKids: []string{"ec-1"},
+		},
+		{
+			name:    "no usable key",
+			keys:    []jsonWebKey{{Kty: "OKP", Kid: "ed-1", Crv: "Ed25519"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks, err := NewKeySet(writeKeySet(t, tt.keys...), 0)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("NewKeySet() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewKeySet: %v", err)
+			}
+			defer ks.Close()
+
+			if len(ks.keys) != len(tt.wantKids) {
+				t.Errorf("loaded %d keys, want %d", len(ks.keys), len(tt.wantKids))
+			}
+			for _, kid := range tt.wantKids {
+				if _, err := ks.Key(kid); err != nil {
+					t.Errorf("Key(%q): %v", kid, err)
+				}
+			}
+		})
+	}
+}
+
+// jwksServer - Serves whichever key set is current and counts the requests.
+type jwksServer struct {
+	mu       sync.Mutex
+	set      jsonWebKeySet
+	status   int
+	requests atomic.Int32
+}
+
+func (s *jwksServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(s.set)
+}
+
+func (s *jwksServer) update(status int, keys ...jsonWebKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+	s.set = jsonWebKeySet{Keys: keys}
+}
+
+// expireRefreshGap - Lets the next unknown kid trigger a reload straight away.
+func expireRefreshGap(ks *KeySet) {
+	ks.mu.Lock()
+	ks.lastRefresh = time.Now().Add(-minRefreshGap)
+	ks.mu.Unlock()
+}
+
+func TestKeySetRotation(t *testing.T) {
+	_, oldKey := rsaJWK(t, "old")
+	_, newKey := rsaJWK(t, "new")
+
+	jwks := &jwksServer{}
+	jwks.update(0, oldKey)
+	server := httptest.NewServer(http.HandlerFunc(jwks.serve))
+	defer server.Close()
+
+	ks, err := NewKeySet(server.URL, 0)
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	defer ks.Close()
+
+	// The new key is published next to one this service cannot read.
+	jwks.update(0, oldKey, newKey, jsonWebKey{Kty: "OKP", Kid: "ed-1", Crv: "Ed25519"})
+
+	if _, err := ks.Key("new"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Key() within the refresh gap: error = %v, want %v", err, ErrKeyNotFound)
+	}
+
+	expireRefreshGap(ks)
+	if _, err := ks.Key("new"); err != nil {
+		t.Fatalf("Key() after rotation: %v", err)
+	}
+
+	// A failed refresh keeps the last good set.
+	jwks.update(http.StatusInternalServerError)
+	if err := ks.Refresh(); err == nil {
+		t.Fatal("Refresh() error = nil, want an error")
+	}
+	if ks.LastError() == nil {
+		t.Error("LastError() = nil after a failed refresh")
+	}
+	if _, err := ks.Key("old"); err != nil {
+		t.Errorf("Key() after a failed refresh: %v", err)
+	}
+}
+
+func TestKeySetUnknownKidRefreshesOnce(t *testing.T) {
+	_, key := rsaJWK(t, "known")
+
+	jwks := &jwksServer{}
+	jwks.update(0, key)
+	server := httptest.NewServer(http.HandlerFunc(jwks.serve))
+	defer server.Close()
+
+	ks, err := NewKeySet(server.URL, 0)
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	defer ks.Close()
+
+	expireRefreshGap(ks)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = ks.Key("unknown")
+		}()
+	}
+	wg.Wait()
+
+	// One request for the initial load and one for the unknown kid.
+	if got := jwks.requests.Load(); got != 2 {
+		t.Errorf("key set fetched %d times, want 2", got)
+	}
+}
+
+func TestVerifierWithKeySet(t *testing.T) {
+	rsaPrivate, rsaKey := rsaJWK(t, "rsa-1")
+	ecPrivate, ecKey := ecJWK(t, "ec-1")
+
+	verifier, err := NewVerifier(config.AuthConfig{JWKS: writeKeySet(t, rsaKey, ecKey)})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+	defer verifier.Close()
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, jwt.MapClaims{"sub": "teacher-1", "exp": time.Now().Add(time.Hour).Unix()})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "RSA key", token: sign(jwt.SigningMethodRS256, "rsa-1", rsaPrivate)},
+		{name: "EC key", token: sign(jwt.SigningMethodES256, "ec-1", ecPrivate)},
+		{name: "unknown kid", token: sign(jwt.SigningMethodRS256, "rsa-2", rsaPrivate), wantErr: ErrKeyNotFound},
+		{name: "algorithm does not match key", token: sign(jwt.SigningMethodES256, "rsa-1", ecPrivate), wantErr: ErrUnsupportedMethod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(tt.token)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Verify: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
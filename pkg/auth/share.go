@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"store/config"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// shareTokenType - The token_type claim that keeps share tokens apart from user and service tokens.
+const shareTokenType = "cart_share"
+
+var (
+	ErrNoShareKey        = errors.New("no share link signing key configured")
+	ErrInvalidShareToken = errors.New("invalid share token")
+)
+
+// ShareTokenSigner - Signs and checks the tokens in cart share links. A token only names the stored
+// link it belongs to and when it expires; revocation is checked against the stored link.
+type ShareTokenSigner struct {
+	key    []byte
+	issuer string
+	parser *jwt.Parser
+}
+
+// NewShareTokenSigner - Builds a signer from configuration. The key is an HMAC secret used with HS256.
+func NewShareTokenSigner(cfg config.ShareLinkConfig) (*ShareTokenSigner, error) {
+	if cfg.SigningKey == "" {
+		return nil, ErrNoShareKey
+	}
+
+	return &ShareTokenSigner{
+		key:    []byte(cfg.SigningKey),
+		issuer: cfg.Issuer,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithExpirationRequired(),
+		),
+	}, nil
+}
+
+// Sign - Issues a token for the share link that stops working at expiresAt.
+func (s *ShareTokenSigner) Sign(linkID string, expiresAt time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"iss":        s.issuer,
+		"sub":        linkID,
+		"iat":        time.Now().Unix(),
+		"exp":        expiresAt.Unix(),
+		"token_type": shareTokenType,
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.key)
+}
+
+// Verify - Checks the signature and expiry of a share token and returns the link ID it was issued for.
+func (s *ShareTokenSigner) Verify(tokenString string) (string, error) {
+	token, err := s.parser.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return s.key, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidShareToken, err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["token_type"] != shareTokenType {
+		return "", ErrInvalidShareToken
+	}
+
+	linkID, err := claims.GetSubject()
+	if err != nil || linkID == "" {
+		return "", ErrInvalidShareToken
+	}
+
+	return linkID, nil
+}
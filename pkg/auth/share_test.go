@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"errors"
+	"store/config"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestShareTokenSigner(t *testing.T) {
+	signer, err := NewShareTokenSigner(config.ShareLinkConfig{SigningKey: "share-secret", Issuer: "cart-service"})
+	if err != nil {
+		t.Fatalf("NewShareTokenSigner: %v", err)
+	}
+
+	sign := func(expiresAt time.Time) string {
+		token, err := signer.Sign("6650c0ffee0000000000abcd", expiresAt)
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		return token
+	}
+
+	other, err := NewShareTokenSigner(config.ShareLinkConfig{SigningKey: "other-secret", Issuer: "cart-service"})
+	if err != nil {
+		t.Fatalf("NewShareTokenSigner: %v", err)
+	}
+	otherToken, _ := other.Sign("6650c0ffee0000000000abcd", time.Now().Add(time.Hour))
+
+	otherIssuer, err := NewShareTokenSigner(config.ShareLinkConfig{SigningKey: "share-secret", Issuer: "someone-else"})
+	if err != nil {
+		t.Fatalf("NewShareTokenSigner: %v", err)
+	}
+	otherIssuerToken, _ := otherIssuer.Sign("6650c0ffee0000000000abcd", time.Now().Add(time.Hour))
+
+	// A service token signed with the same secret is not a share token.
+	serviceToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":        "cart-service",
+		"sub":        "6650c0ffee0000000000abcd",
+		"exp":        time.Now().Add(time.Hour).Unix(),
+		"token_type": "service",
+	}).SignedString([]byte("share-secret"))
+
+	noExpiry, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":        "cart-service",
+		"sub":        "6650c0ffee0000000000abcd",
+		"token_type": shareTokenType,
+	}).SignedString([]byte("share-secret"))
+
+	tests := []struct {
+		name       string
+		token      string
+		wantLinkID string
+	}{
+		{name: "valid", token: sign(time.Now().Add(time.Hour)), wantLinkID: "6650c0ffee0000000000abcd"},
+		{name: "expired", token: sign(time.Now().Add(-time.Minute))},
+		{name: "signed with another key", token: otherToken},
+		{name: "another issuer", token: otherIssuerToken},
+		{name: "not a share token", token: serviceToken},
+		{name: "no expiry", token: noExpiry},
+		{name: "malformed", token: "not-a-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linkID, err := signer.Verify(tt.token)
+			if tt.wantLinkID == "" {
+				if !errors.Is(err, ErrInvalidShareToken) {
+					t.Errorf("Verify() = %q, %v, want %v", linkID, err, ErrInvalidShareToken)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if linkID != tt.wantLinkID {
+				t.Errorf("Verify() = %q, want %q", linkID, tt.wantLinkID)
+			}
+		})
+	}
+}
+
+func TestNewShareTokenSignerWithoutKey(t *testing.T) {
+	if _, err := NewShareTokenSigner(config.ShareLinkConfig{}); !errors.Is(err, ErrNoShareKey) {
+		t.Errorf("NewShareTokenSigner() error = %v, want %v", err, ErrNoShareKey)
+	}
+}
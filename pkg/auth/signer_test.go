@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"store/config"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestServiceTokenSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	rsaPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ECDSA key: %v", err)
+	}
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatalf("failed to encode ECDSA key: %v", err)
+	}
+	ecPath := filepath.Join(t.TempDir(), "service.pem")
+	if err := os.WriteFile(ecPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}), 0o600); err != nil {
+		t.Fatalf("failed to write ECDSA key: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		cfg       config.ServiceAuthConfig
+		verifyKey interface{}
+	}{
+		{
+			name:      "HMAC secret",
+			cfg:       config.ServiceAuthConfig{SigningKey: "service-secret", Algorithm: "HS256"},
+			verifyKey: []byte("service-secret"),
+		},
+		{
+			name:      "RSA key as PEM content",
+			cfg:       config.ServiceAuthConfig{SigningKey: rsaPEM, Algorithm: "RS256", KeyID: "cart-2024"},
+			verifyKey: &rsaKey.PublicKey,
+		},
+		{
+			name:      "ECDSA key as a file path",
+			cfg:       config.ServiceAuthConfig{SigningKey: ecPath, Algorithm: "ES256"},
+			verifyKey: &ecKey.PublicKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Issuer = "cart-service"
+			tt.cfg.TTL = time.Minute
+
+			signer, err := NewServiceTokenSigner(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewServiceTokenSigner: %v", err)
+			}
+
+			signed, err := signer.Mint("order-service", "teacher-1")
+			if err != nil {
+				t.Fatalf("Mint: %v", err)
+			}
+
+			claims := jwt.MapClaims{}
+			token, err := jwt.NewParser(
+				jwt.WithValidMethods([]string{tt.cfg.Algorithm}),
+				jwt.WithIssuer("cart-service"),
+				jwt.WithAudience("order-service"),
+				jwt.WithExpirationRequired(),
+			).ParseWithClaims(signed, claims, func(*jwt.Token) (interface{}, error) { return tt.verifyKey, nil })
+			if err != nil {
+				t.Fatalf("minted token does not verify: %v", err)
+			}
+
+			if claims[OnBehalfOfClaim] != "teacher-1" || claims["token_type"] != "service" || claims["jti"] == "" {
+				t.Errorf("claims = %v", claims)
+			}
+			if kid, _ := token.Header["kid"].(string); kid != tt.cfg.KeyID {
+				t.Errorf("kid = %q, want %q", kid, tt.cfg.KeyID)
+			}
+
+			exp, _ := claims.GetExpirationTime()
+			if exp == nil || exp.Sub(time.Now()) > time.Minute {
+				t.Errorf("exp = %v, want within the TTL", exp)
+			}
+		})
+	}
+}
+
+func TestServiceTokenSignerWithoutUser(t *testing.T) {
+	signer, err := NewServiceTokenSigner(config.ServiceAuthConfig{SigningKey: "service-secret", Algorithm: "HS256", TTL: time.Minute})
+	if err != nil {
+		t.Fatalf("NewServiceTokenSigner: %v", err)
+	}
+
+	signed, err := signer.Mint("product-service", "")
+	if err != nil {
+		t.Fatalf("Mint: %v", err)
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(signed, claims, func(*jwt.Token) (interface{}, error) { return []byte("service-secret"), nil }); err != nil {
+		t.Fatalf("minted token does not verify: %v", err)
+	}
+	if _, ok := claims[OnBehalfOfClaim]; ok {
+		t.Errorf("on_behalf_of is set without a user: %v", claims)
+	}
+}
+
+func TestNewServiceTokenSignerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.ServiceAuthConfig
+		wantErr error
+	}{
+		{name: "no key", cfg: config.ServiceAuthConfig{Algorithm: "HS256"}, wantErr: ErrNoSigningKey},
+		{name: "unknown algorithm", cfg: config.ServiceAuthConfig{SigningKey: "secret", Algorithm: "XS256"}, wantErr: ErrUnsupportedMethod},
+		{name: "none algorithm", cfg: config.ServiceAuthConfig{SigningKey: "secret", Algorithm: "none"}, wantErr: ErrUnsupportedMethod},
+		{name: "RSA algorithm without a PEM key", cfg: config.ServiceAuthConfig{SigningKey: "-----BEGIN nothing", Algorithm: "RS256"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewServiceTokenSigner(tt.cfg)
+			if err == nil {
+				t.Fatal("NewServiceTokenSigner() error = nil, want an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewServiceTokenSigner() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
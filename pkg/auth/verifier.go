@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"store/config"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrNoVerificationKey = errors.New("no token verification key configured")
+	ErrUnsupportedMethod = errors.New("unsupported signing method")
+)
+
+// Verifier - Checks token signatures and the standard registered claims.
+type Verifier struct {
+	hmacSecret []byte
+	rsaKey     *rsa.PublicKey
+	ecdsaKey   *ecdsa.PublicKey
+	keySet     *KeySet
+	rolesClaim string
+	parser     *jwt.Parser
+}
+
+// NewVerifier - Builds a Verifier from the auth section of the configuration.
+// Public keys may be given either as PEM content or as a path to a PEM file, and a JWKS
+// document (file or URL) may be configured for keys that are selected by the token's kid.
+func NewVerifier(cfg config.AuthConfig) (*Verifier, error) {
+	v := &Verifier{rolesClaim: cfg.RolesClaim}
+	var methods []string
+
+	if cfg.HMACSecret != "" {
+		v.hmacSecret = []byte(cfg.HMACSecret)
+		methods = append(methods, "HS256", "HS384", "HS512")
+	}
+
+	if cfg.RSAPublicKey != "" {
+		pem, err := readPEM(cfg.RSAPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read RSA public key: %w", err)
+		}
+		v.rsaKey, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA public key: %w", err)
+		}
+		methods = append(methods, "RS256", "RS384", "RS512", "PS256", "PS384", "PS512")
+	}
+
+	if cfg.ECDSAPublicKey != "" {
+		pem, err := readPEM(cfg.ECDSAPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ECDSA public key: %w", err)
+		}
+		v.ecdsaKey, err = jwt.ParseECPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ECDSA public key: %w", err)
+		}
+		methods = append(methods, "ES256", "ES384", "ES512")
+	}
+
+	if cfg.JWKS != "" {
+		keySet, err := NewKeySet(cfg.JWKS, cfg.JWKSRefreshInterval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load key set: %w", err)
+		}
+		v.keySet = keySet
+		methods = append(methods, "RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "HS256", "HS384", "HS512")
+	}
+
+	if len(methods) == 0 {
+		return nil, ErrNoVerificationKey
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	v.parser = jwt.NewParser(opts...)
+	return v, nil
+}
+
+// Verify - Parses the token, checks its signature, exp, nbf, iss and aud, and returns its claims.
+func (v *Verifier) Verify(tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(tokenString, claims, v.keyFunc); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Roles - Collects the roles and scopes granted by the token. Roles are read from the
+// configured roles claim, scopes from the standard "scope" and "scp" claims; each may be
+// either a list or a space-separated string.
+func (v *Verifier) Roles(claims jwt.MapClaims) []string {
+	seen := map[string]bool{}
+	var roles []string
+
+	for _, name := range []string{v.rolesClaim, "scope", "scp"} {
+		if name == "" {
+			continue
+		}
+		for _, role := range claimValues(claims[name]) {
+			if !seen[role] {
+				seen[role] = true
+				roles = append(roles, role)
+			}
+		}
+	}
+
+	return roles
+}
+
+// Close - Releases the background key set refresh, if any.
+func (v *Verifier) Close() {
+	if v.keySet != nil {
+		v.keySet.Close()
+	}
+}
+
+func (v *Verifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	if kid, ok := token.Header["kid"].(string); ok && kid != "" && v.keySet != nil {
+		key, err := v.keySet.Key(kid)
+		if err != nil {
+			return nil, err
+		}
+		if !keyMatchesMethod(key, token.Method) {
+			return nil, fmt.Errorf("%w: key %q cannot verify %v", ErrUnsupportedMethod, kid, token.Header["alg"])
+		}
+		return key, nil
+	}
+
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if v.hmacSecret != nil {
+			return v.hmacSecret, nil
+		}
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		if v.rsaKey != nil {
+			return v.rsaKey, nil
+		}
+	case *jwt.SigningMethodECDSA:
+		if v.ecdsaKey != nil {
+			return v.ecdsaKey, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %v", ErrUnsupportedMethod, token.Header["alg"])
+}
+
+func claimValues(raw interface{}) []string {
+	switch value := raw.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		var values []string
+		for _, item := range value {
+			if str, ok := item.(string); ok && str != "" {
+				values = append(values, str)
+			}
+		}
+		return values
+	case []string:
+		return value
+	}
+	return nil
+}
+
+func keyMatchesMethod(key interface{}, method jwt.SigningMethod) bool {
+	switch method.(type) {
+	case *jwt.SigningMethodHMAC:
+		_, ok := key.([]byte)
+		return ok
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := key.(*rsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodECDSA:
+		_, ok := key.(*ecdsa.PublicKey)
+		return ok
+	}
+	return false
+}
+
+func readPEM(value string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
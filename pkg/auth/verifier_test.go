@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"errors"
+	"reflect"
+	"store/config"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret"
+
+func signHS256(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func TestVerifierRoles(t *testing.T) {
+	verifier, err := NewVerifier(config.AuthConfig{HMACSecret: testSecret, RolesClaim: "roles"})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   []string
+	}{
+		{
+			name:   "roles claim as a list",
+			claims: jwt.MapClaims{"roles": []string{"admin", "supervisor"}},
+			want:   []string{"admin", "supervisor"},
+		},
+		{
+			name:   "roles claim as a string",
+			claims: jwt.MapClaims{"roles": "admin supervisor"},
+			want:   []string{"admin", "supervisor"},
+		},
+		{
+			name:   "scope and scp",
+			claims: jwt.MapClaims{"scope": "cart:read", "scp": []string{"cart:write"}},
+			want:   []string{"cart:read", "cart:write"},
+		},
+		{
+			name:   "roles and scopes are deduplicated",
+			claims: jwt.MapClaims{"roles": []string{"admin"}, "scope": "admin cart:read"},
+			want:   []string{"admin", "cart:read"},
+		},
+		{
+			name:   "no roles",
+			claims: jwt.MapClaims{},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.claims["sub"] = "teacher-1"
+			tt.claims["exp"] = time.Now().Add(time.Hour).Unix()
+
+			claims, err := verifier.Verify(signHS256(t, testSecret, tt.claims))
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+
+			if got := verifier.Roles(claims); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Roles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifierRolesClaimName(t *testing.T) {
+	verifier, err := NewVerifier(config.AuthConfig{HMACSecret: testSecret, RolesClaim: "groups"})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	claims := jwt.MapClaims{"roles": []interface{}{"admin"}, "groups": []interface{}{"supervisor"}}
+
+	if got, want := verifier.Roles(claims), []string{"supervisor"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Roles() = %v, want %v", got, want)
+	}
+}
+
+func TestVerifierVerify(t *testing.T) {
+	verifier, err := NewVerifier(config.AuthConfig{
+		HMACSecret: testSecret,
+		Issuer:     "https://idp.example.com",
+		Audience:   "store",
+	})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": "teacher-1",
+			"iss": "https://idp.example.com",
+			"aud": "store",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		secret  string
+		modify  func(claims jwt.MapClaims)
+		wantErr error
+	}{
+		{
+			name:   "valid token",
+			secret: testSecret,
+			modify: func(claims jwt.MapClaims) {},
+		},
+		{
+			name:    "wrong secret",
+			secret:  "other-secret",
+			modify:  func(claims jwt.MapClaims) {},
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:    "expired",
+			secret:  testSecret,
+			modify:  func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+			wantErr: jwt.ErrTokenExpired,
+		},
+		{
+			name:    "missing exp",
+			secret:  testSecret,
+			modify:  func(claims jwt.MapClaims) { delete(claims, "exp") },
+			wantErr: jwt.ErrTokenRequiredClaimMissing,
+		},
+		{
+			name:    "wrong issuer",
+			secret:  testSecret,
+			modify:  func(claims jwt.MapClaims) { claims["iss"] = "https://other.example.com" },
+			wantErr: jwt.ErrTokenInvalidIssuer,
+		},
+		{
+			name:    "wrong audience",
+			secret:  testSecret,
+			modify:  func(claims jwt.MapClaims) { claims["aud"] = "billing" },
+			wantErr: jwt.ErrTokenInvalidAudience,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.modify(claims)
+
+			_, err := verifier.Verify(signHS256(t, tt.secret, claims))
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Verify: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewVerifierWithoutKey(t *testing.T) {
+	if _, err := NewVerifier(config.AuthConfig{}); !errors.Is(err, ErrNoVerificationKey) {
+		t.Errorf("NewVerifier() error = %v, want %v", err, ErrNoVerificationKey)
+	}
+}
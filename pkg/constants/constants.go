@@ -105,7 +105,12 @@ const (
 	MinimumUsageTime = "minimum_usage_time"
 	MaximumUsageTime = "maximum_usage_time"
 
-	UserID = "user_id"
+	UserID    = "user_id"
+	Roles     = "roles"
+	RequestID = "request_id"
+
+	RoleAdmin      = "admin"
+	RoleSupervisor = "supervisor"
 )
 
 type contextKey string
@@ -116,4 +121,5 @@ func (c contextKey) String() string {
 
 var (
 	TokenKey = contextKey("token")
+	ActorKey = contextKey("actor")
 )
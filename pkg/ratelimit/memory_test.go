@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreAllow(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		limit       Limit
+		requests    int
+		wantAllowed int
+	}{
+		{name: "unlimited", limit: Limit{}, requests: 100, wantAllowed: 100},
+		{name: "burst", limit: Limit{Rate: 0.001, Burst: 3}, requests: 5, wantAllowed: 3},
+		{name: "burst of one", limit: Limit{Rate: 0.001, Burst: 1}, requests: 2, wantAllowed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+
+			allowed := 0
+			var last Result
+			for i := 0; i < tt.requests; i++ {
+				result, err := store.Allow(ctx, "user|route", tt.limit)
+				if err != nil {
+					t.Fatalf("Allow: %v", err)
+				}
+				if result.Allowed {
+					allowed++
+				}
+				last = result
+			}
+
+			if allowed != tt.wantAllowed {
+				t.Errorf("allowed %d of %d requests, want %d", allowed, tt.requests, tt.wantAllowed)
+			}
+			if allowed < tt.requests && (last.Remaining != 0 || last.RetryAfter <= 0) {
+				t.Errorf("refused result = %+v, want no tokens left and a retry delay", last)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreKeysAndRefill(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore().(*memoryStore)
+	limit := Limit{Rate: 1, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		if result, _ := store.Allow(ctx, "a", limit); !result.Allowed {
+			t.Fatalf("request %d refused", i+1)
+		}
+	}
+	if result, _ := store.Allow(ctx, "a", limit); result.Allowed {
+		t.Fatal("request past the burst allowed")
+	}
+
+	// Buckets are kept apart per key.
+	if result, _ := store.Allow(ctx, "b", limit); !result.Allowed || result.Remaining != 1 {
+		t.Errorf("other key = %+v, want allowed with 1 remaining", result)
+	}
+
+	// Tokens come back at the configured rate.
+	store.buckets["a"].updated = store.buckets["a"].updated.Add(-1500 * time.Millisecond)
+	if result, _ := store.Allow(ctx, "a", limit); !result.Allowed {
+		t.Error("request after a refill refused")
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore().(*memoryStore)
+	limit := Limit{Rate: 1, Burst: 2}
+
+	_, _ = store.Allow(ctx, "idle", limit)
+	store.buckets["idle"].updated = time.Now().Add(-time.Hour)
+	store.lastSweep = time.Now().Add(-2 * sweepInterval)
+
+	_, _ = store.Allow(ctx, "active", limit)
+
+	if _, ok := store.buckets["idle"]; ok {
+		t.Error("idle bucket was not swept")
+	}
+	if _, ok := store.buckets["active"]; !ok {
+		t.Error("active bucket was swept")
+	}
+}
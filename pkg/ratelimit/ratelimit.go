@@ -0,0 +1,29 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit - Token bucket settings: Rate tokens are added per second, up to Burst tokens.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Unlimited - Reports whether the limit disables throttling.
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// Result - Outcome of taking one token from a bucket.
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Store - Keeps token buckets keyed by an arbitrary string.
+type Store interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
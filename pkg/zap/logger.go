@@ -97,6 +97,13 @@ func New(cfg *config.Config) (*appLogger, error) {
 		zap.NewAtomicLevelAt(level),
 	)
 
+	// Mask personal data before anything is encoded
+	redactor, err := NewRedactor(cfg.Zap.Redaction)
+	if err != nil {
+		return nil, err
+	}
+	core = NewRedactingCore(core, redactor)
+
 	// Create logger options
 	var opts []zap.Option
 	if cfg.Zap.Development {
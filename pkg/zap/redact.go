@@ -0,0 +1,188 @@
+package zap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"store/config"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const redactedMask = "[REDACTED]"
+
+var (
+	defaultRedactedFields = []string{"email", "phone", "street", "address", "password", "token", "authorization"}
+
+	defaultRedactedPatterns = []string{
+		// Email addresses
+		`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`,
+		// International phone numbers, which always start with "+" and a country code: +84 912 345 678
+		`\+\d{1,3}(?:[\s\-.]?\(?\d{2,4}\)?){2,4}\b`,
+		// National phone numbers with a trunk "0" or a bracketed area code, and separated digit groups:
+		// 0912-345-678, 028 3822 1234, (555) 123-4567. Bare runs of digits are left alone, they are
+		// usually order numbers, amounts or IDs.
+		`(?:\b0\d{1,3}|\(\d{2,4}\))[\s\-.]?\d{3,4}[\s\-.]\d{3,4}\b`,
+	}
+)
+
+// Redactor - Masks personal data. Values of configured field names are replaced entirely,
+// and configured patterns are masked wherever they appear inside other strings.
+type Redactor struct {
+	fields   map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor - Builds a Redactor from configuration. Empty lists fall back to the built-in defaults.
+func NewRedactor(cfg config.RedactionConfig) (*Redactor, error) {
+	fields := cfg.Fields
+	if len(fields) == 0 {
+		fields = defaultRedactedFields
+	}
+
+	patterns := cfg.Patterns
+	if len(patterns) == 0 {
+		patterns = defaultRedactedPatterns
+	}
+
+	r := &Redactor{fields: make(map[string]bool, len(fields))}
+	for _, field := range fields {
+		r.fields[strings.ToLower(strings.TrimSpace(field))] = true
+	}
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	return r, nil
+}
+
+// DefaultRedactor - A Redactor using the built-in field names and patterns.
+func DefaultRedactor() *Redactor {
+	r, _ := NewRedactor(config.RedactionConfig{})
+	return r
+}
+
+// IsSensitive - Reports whether values under this field name are always masked.
+func (r *Redactor) IsSensitive(field string) bool {
+	return r.fields[strings.ToLower(field)]
+}
+
+// String - Masks every pattern match in s.
+func (r *Redactor) String(s string) string {
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, redactedMask)
+	}
+	return s
+}
+
+// Error - Returns an error whose message has been masked, or nil.
+func (r *Redactor) Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	return errors.New(r.String(err.Error()))
+}
+
+// Field - Masks a structured log field.
+func (r *Redactor) Field(field zapcore.Field) zapcore.Field {
+	if r.IsSensitive(field.Key) {
+		return zap.String(field.Key, redactedMask)
+	}
+
+	switch field.Type {
+	case zapcore.StringType:
+		return zap.String(field.Key, r.String(field.String))
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok {
+			return zap.String(field.Key, r.String(err.Error()))
+		}
+	case zapcore.StringerType:
+		if stringer, ok := field.Interface.(fmt.Stringer); ok {
+			return zap.String(field.Key, r.String(stringer.String()))
+		}
+	case zapcore.ReflectType:
+		return zap.Any(field.Key, r.value(field.Interface))
+	}
+
+	return field
+}
+
+// value - Masks nested values by round-tripping them through JSON so struct tags decide the field names.
+func (r *Redactor) value(v interface{}) interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return v
+	}
+
+	return r.walk(decoded)
+}
+
+func (r *Redactor) walk(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, nested := range value {
+			if r.IsSensitive(key) {
+				value[key] = redactedMask
+				continue
+			}
+			value[key] = r.walk(nested)
+		}
+		return value
+	case []interface{}:
+		for i, nested := range value {
+			value[i] = r.walk(nested)
+		}
+		return value
+	case string:
+		return r.String(value)
+	default:
+		return value
+	}
+}
+
+// redactingCore - Wraps a zapcore.Core so messages and fields are masked before they are encoded.
+type redactingCore struct {
+	zapcore.Core
+	redactor *Redactor
+}
+
+func NewRedactingCore(core zapcore.Core, redactor *Redactor) zapcore.Core {
+	return &redactingCore{Core: core, redactor: redactor}
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.redactFields(fields)), redactor: c.redactor}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.redactor.String(entry.Message)
+	return c.Core.Write(entry, c.redactFields(fields))
+}
+
+func (c *redactingCore) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = c.redactor.Field(field)
+	}
+	return redacted
+}
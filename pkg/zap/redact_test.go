@@ -0,0 +1,117 @@
+package zap
+
+import (
+	"errors"
+	"reflect"
+	"store/config"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedactorString(t *testing.T) {
+	redactor := DefaultRedactor()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "email", in: "order confirmation sent to jane.doe+school@example.co.uk", want: "order confirmation sent to [REDACTED]"},
+		{name: "international phone", in: "call +84 912 345 678 tomorrow", want: "call [REDACTED] tomorrow"},
+		{name: "local phone with dashes", in: "phone: 0912-345-678", want: "phone: [REDACTED]"},
+		{name: "international phone without separators", in: "phone +84912345678.", want: "phone [REDACTED]."},
+		{name: "bracketed area code", in: "call (555) 123-4567", want: "call [REDACTED]"},
+		{name: "email and phone", in: "a@b.io / 028 3822 1234", want: "[REDACTED] / [REDACTED]"},
+		{name: "object ID is kept", in: "cart 6650c0ffee0000000000abcd not found", want: "cart 6650c0ffee0000000000abcd not found"},
+		{name: "date is kept", in: "ordered on 2024-05-01", want: "ordered on 2024-05-01"},
+		{name: "short numbers are kept", in: "quantity 12 exceeds the limit of 10", want: "quantity 12 exceeds the limit of 10"},
+		{name: "order number is kept", in: "order 202405011234 was not created", want: "order 202405011234 was not created"},
+		{name: "long numeric ID is kept", in: "student 0912345678 has no teacher", want: "student 0912345678 has no teacher"},
+		{name: "amount is kept", in: "store total 1250000.50 exceeds 1000000.00", want: "store total 1250000.50 exceeds 1000000.00"},
+		{name: "grouped amount is kept", in: "budget 1 250 000 left", want: "budget 1 250 000 left"},
+		{name: "dashed reference is kept", in: "invoice 2024-0001-1234 is closed", want: "invoice 2024-0001-1234 is closed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactor.String(tt.in); got != tt.want {
+				t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRedactor(t *testing.T) {
+	redactor, err := NewRedactor(config.RedactionConfig{Fields: []string{" Student_Name "}, Patterns: []string{`S\d{6}`}})
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+
+	if !redactor.IsSensitive("student_name") || redactor.IsSensitive("email") {
+		t.Error("configured fields should replace the default ones")
+	}
+	if got := redactor.String("student S123456, jane@example.com"); got != "student [REDACTED], jane@example.com" {
+		t.Errorf("String() = %q", got)
+	}
+
+	if _, err := NewRedactor(config.RedactionConfig{Patterns: []string{"("}}); err == nil {
+		t.Error("NewRedactor() accepted an invalid pattern")
+	}
+}
+
+func TestRedactorField(t *testing.T) {
+	redactor := DefaultRedactor()
+
+	type address struct {
+		Street string `json:"street"`
+		City   string `json:"city"`
+		Note   string `json:"note"`
+	}
+
+	tests := []struct {
+		name  string
+		field zapcore.Field
+		want  interface{}
+	}{
+		{name: "sensitive field name", field: zap.String("Email", "not-an-email"), want: "[REDACTED]"},
+		{name: "pattern in a string", field: zap.String("message", "contact jane@example.com"), want: "contact [REDACTED]"},
+		{name: "pattern in an error", field: zap.Error(errors.New("no teacher for jane@example.com")), want: "no teacher for [REDACTED]"},
+		{name: "other types are kept", field: zap.Int("quantity", 3), want: int64(3)},
+		{
+			name:  "nested values",
+			field: zap.Any("request", address{Street: "1 Main St", City: "Hanoi", Note: "call 0912-345-678"}),
+			want:  map[string]interface{}{"street": "[REDACTED]", "city": "Hanoi", "note": "call [REDACTED]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			zap.New(core).Info("test", redactor.Field(tt.field))
+
+			got := logs.All()[0].ContextMap()[tt.field.Key]
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("field %s = %#v, want %#v", tt.field.Key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactingCore(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(NewRedactingCore(core, DefaultRedactor())).With(zap.String("phone", "0912345678"))
+
+	logger.Info("checkout for jane@example.com", zap.String("street", "1 Main St"))
+
+	entry := logs.All()[0]
+	if entry.Message != "checkout for [REDACTED]" {
+		t.Errorf("message = %q", entry.Message)
+	}
+	want := map[string]interface{}{"phone": "[REDACTED]", "street": "[REDACTED]"}
+	if got := entry.ContextMap(); !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+}